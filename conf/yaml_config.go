@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -17,6 +19,10 @@ const (
 	DefaultLoggingFile           = ""
 	DefaultKeepReports           = true
 	DefaultCoverPackageName      = "*"
+	DefaultOutputFormat          = "table"
+	DefaultOutputPath            = "-"
+	DefaultUntestedPolicy        = "fail"
+	DefaultBaselineFile          = ".coverco-baseline.json"
 )
 
 var (
@@ -24,27 +30,142 @@ var (
 	DefaultCoverPackages   = []struct {
 		Name      string   `yaml:"name"`
 		Threshold *float64 `yaml:"threshold,omitempty"`
+		Priority  *int     `yaml:"priority,omitempty"`
 	}{
-		{Name: DefaultCoverPackageName, Threshold: nil}, // Default cover all packages
+		{Name: DefaultCoverPackageName, Threshold: nil, Priority: nil}, // Default cover all packages
 	}
 )
 
+// OutputTarget selects an output format and the file it is written to.
+// A path of "-" writes to stdout.
+type OutputTarget struct {
+	Format string `yaml:"format"`
+	Path   string `yaml:"path"`
+}
+
+// ParseOutputTarget parses a "format=path" pair. A bare format writes to stdout.
+func ParseOutputTarget(value string) (OutputTarget, error) {
+	format, path, found := strings.Cut(value, "=")
+	format = strings.TrimSpace(format)
+	path = strings.TrimSpace(path)
+	if format == "" {
+		return OutputTarget{}, fmt.Errorf("invalid output %q: missing format", value)
+	}
+	if !found || path == "" {
+		path = DefaultOutputPath
+	}
+	return OutputTarget{Format: format, Path: path}, nil
+}
+
+// outputFlag collects output targets from repeated or comma-separated -output flags
+type outputFlag []OutputTarget
+
+func (o *outputFlag) String() string {
+	pairs := make([]string, 0, len(*o))
+	for _, target := range *o {
+		pairs = append(pairs, target.Format+"="+target.Path)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (o *outputFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		target, err := ParseOutputTarget(pair)
+		if err != nil {
+			return err
+		}
+		*o = append(*o, target)
+	}
+	return nil
+}
+
+// subtreeThresholdFlag collects pattern=percent pairs from repeated or comma-separated -subtree-threshold flags
+type subtreeThresholdFlag map[string]float64
+
+func (t subtreeThresholdFlag) String() string {
+	pairs := make([]string, 0, len(t))
+	for pattern, threshold := range t {
+		pairs = append(pairs, fmt.Sprintf("%s=%g", pattern, threshold))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (t subtreeThresholdFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pattern, percent, found := strings.Cut(pair, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !found || pattern == "" {
+			return fmt.Errorf("invalid subtree threshold %q: expected pattern=percent", pair)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			return fmt.Errorf("invalid subtree threshold %q: %w", pair, err)
+		}
+		t[pattern] = threshold
+	}
+	return nil
+}
+
 // Config represents the configuration file structure
 type Config struct {
 	DefaultCoverageThreshold float64 `yaml:"default_coverage_threshold"`
+	GlobalThreshold          float64 `yaml:"global_threshold"`
 	CoverageReportsDir       string  `yaml:"coverage_reports_dir"`
 	CoverageReportsFormat    string  `yaml:"coverage_reports_format"`
 
 	CoverPackages []struct {
 		Name      string   `yaml:"name"`
 		Threshold *float64 `yaml:"threshold,omitempty"`
+		Priority  *int     `yaml:"priority,omitempty"`
 	} `yaml:"cover_packages"`
 	ExcludePackages []string `yaml:"exclude_packages"`
+	CoverPkg        []string `yaml:"coverpkg"`
 	Logging         struct {
 		Level string `yaml:"level"`
 		File  string `yaml:"file,omitempty"`
 	} `yaml:"logging"`
 	KeepReports bool `yaml:"keep_reports"`
+	Parallelism int  `yaml:"parallelism"`
+
+	Output []OutputTarget `yaml:"output"`
+	Detail bool           `yaml:"detail"`
+	Delta  bool           `yaml:"delta"`
+
+	// Directory receiving an SVG badge per package from the badge output
+	BadgeDir string `yaml:"badge_dir"`
+
+	// Record the individual go test results, reported by the junit output
+	JUnitTests bool `yaml:"junit_tests"`
+
+	// Append every run to a JSON-lines history in the reports directory
+	History bool `yaml:"history"`
+
+	// Patch coverage of the lines changed since the Base git ref
+	Base           string  `yaml:"base"`
+	PatchThreshold float64 `yaml:"patch_threshold"`
+
+	// Only test packages affected by changes since this git ref
+	ChangedSince string `yaml:"changed_since"`
+
+	// Run 'go mod tidy' in the target directory before listing packages
+	Tidy bool `yaml:"tidy"`
+
+	// Aggregate thresholds of import path subtrees, e.g. "internal/...": 75
+	SubtreeThresholds map[string]float64 `yaml:"subtree_thresholds"`
+
+	// Committed per-package coverage that must not drop by more than
+	// Tolerance percentage points; Update raises it when coverage improves
+	Baseline struct {
+		File      string  `yaml:"file"`
+		Tolerance float64 `yaml:"tolerance"`
+		Update    bool    `yaml:"update"`
+	} `yaml:"baseline"`
+
+	// How packages without test files are judged: "fail", "ignore" or "threshold"
+	UntestedPackages struct {
+		Policy    string   `yaml:"policy"`
+		Threshold *float64 `yaml:"threshold,omitempty"`
+	} `yaml:"untested_packages"`
 }
 
 // LoadConfigFromFile loads the configuration from the specified file and overlays it onto the default configuration
@@ -64,6 +185,12 @@ func LoadConfigFromFile(config *Config, configFilePath string) error {
 	if fileConfig.DefaultCoverageThreshold != 0 {
 		config.DefaultCoverageThreshold = fileConfig.DefaultCoverageThreshold
 	}
+	if fileConfig.GlobalThreshold != 0 {
+		config.GlobalThreshold = fileConfig.GlobalThreshold
+	}
+	if len(fileConfig.SubtreeThresholds) > 0 {
+		config.SubtreeThresholds = fileConfig.SubtreeThresholds
+	}
 	if fileConfig.CoverageReportsDir != "" {
 		config.CoverageReportsDir = fileConfig.CoverageReportsDir
 	}
@@ -76,6 +203,9 @@ func LoadConfigFromFile(config *Config, configFilePath string) error {
 	if len(fileConfig.ExcludePackages) > 0 {
 		config.ExcludePackages = fileConfig.ExcludePackages
 	}
+	if len(fileConfig.CoverPkg) > 0 {
+		config.CoverPkg = fileConfig.CoverPkg
+	}
 	if fileConfig.Logging.Level != "" {
 		config.Logging.Level = fileConfig.Logging.Level
 	}
@@ -83,10 +213,72 @@ func LoadConfigFromFile(config *Config, configFilePath string) error {
 		config.Logging.File = fileConfig.Logging.File
 	}
 	config.KeepReports = fileConfig.KeepReports
+	if fileConfig.Parallelism > 0 {
+		config.Parallelism = fileConfig.Parallelism
+	}
+	if fileConfig.Base != "" {
+		config.Base = fileConfig.Base
+	}
+	if fileConfig.PatchThreshold != 0 {
+		config.PatchThreshold = fileConfig.PatchThreshold
+	}
+	if fileConfig.UntestedPackages.Policy != "" {
+		config.UntestedPackages.Policy = fileConfig.UntestedPackages.Policy
+	}
+	if fileConfig.UntestedPackages.Threshold != nil {
+		config.UntestedPackages.Threshold = fileConfig.UntestedPackages.Threshold
+	}
+	if fileConfig.Baseline.File != "" {
+		config.Baseline.File = fileConfig.Baseline.File
+	}
+	if fileConfig.Baseline.Tolerance != 0 {
+		config.Baseline.Tolerance = fileConfig.Baseline.Tolerance
+	}
+	if fileConfig.Baseline.Update {
+		config.Baseline.Update = true
+	}
+	if fileConfig.Tidy {
+		config.Tidy = true
+	}
+	if fileConfig.ChangedSince != "" {
+		config.ChangedSince = fileConfig.ChangedSince
+	}
+	if fileConfig.Detail {
+		config.Detail = true
+	}
+	if fileConfig.Delta {
+		config.Delta = true
+	}
+	if fileConfig.BadgeDir != "" {
+		config.BadgeDir = fileConfig.BadgeDir
+	}
+	if fileConfig.JUnitTests {
+		config.JUnitTests = true
+	}
+	if fileConfig.History {
+		config.History = true
+	}
+	if len(fileConfig.Output) > 0 {
+		config.Output = fileConfig.Output
+		for i := range config.Output {
+			if config.Output[i].Path == "" {
+				config.Output[i].Path = DefaultOutputPath
+			}
+		}
+	}
 
 	return nil
 }
 
+// UntestedThreshold returns the threshold for packages without test files,
+// falling back to the default coverage threshold
+func (c Config) UntestedThreshold() float64 {
+	if c.UntestedPackages.Threshold != nil {
+		return *c.UntestedPackages.Threshold
+	}
+	return c.DefaultCoverageThreshold
+}
+
 // GetDefaultConfig returns a Config struct with default values
 func GetDefaultConfig() Config {
 	return Config{
@@ -102,32 +294,137 @@ func GetDefaultConfig() Config {
 			Level: DefaultLoggingLevel,
 			File:  DefaultLoggingFile,
 		},
-		KeepReports: DefaultKeepReports,
+		KeepReports:    DefaultKeepReports,
+		Parallelism:    runtime.NumCPU(),
+		Output:         []OutputTarget{{Format: DefaultOutputFormat, Path: DefaultOutputPath}},
+		PatchThreshold: DefaultThreshold,
+		Baseline: struct {
+			File      string  `yaml:"file"`
+			Tolerance float64 `yaml:"tolerance"`
+			Update    bool    `yaml:"update"`
+		}{
+			File: DefaultBaselineFile,
+		},
+		UntestedPackages: struct {
+			Policy    string   `yaml:"policy"`
+			Threshold *float64 `yaml:"threshold,omitempty"`
+		}{
+			Policy: DefaultUntestedPolicy,
+		},
 	}
 }
 
+// Flags holds the command line flags that override the configuration. Nil
+// pointers and zero values leave the configuration unchanged.
+type Flags struct {
+	DefaultCoverageThreshold *float64
+	ExcludePatterns          *string
+	CoverageReportsDir       *string
+	CoverageReportsFormat    *string
+	LogLevel                 *string
+	LogFile                  *string
+	KeepReports              *bool
+	Parallelism              *int
+	Outputs                  []OutputTarget
+	Detail                   *bool
+	Base                     *string
+	PatchThreshold           *float64
+	ChangedSince             *string
+	Tidy                     *bool
+	UntestedPolicy           *string
+	CoverPkg                 *string
+	GlobalThreshold          *float64
+	SubtreeThresholds        map[string]float64
+	BaselineFile             *string
+	BaselineTolerance        *float64
+	UpdateBaseline           *bool
+	History                  *bool
+	Delta                    *bool
+	BadgeDir                 *string
+	JUnitTests               *bool
+}
+
 // OverrideWithFlags overrides configuration values with command line flags if they are set
-func OverrideWithFlags(config *Config, defaultCoverageThreshold *float64, excludePatterns, coverageReportsDir, coverageReportsFormat, logLevel, logFile *string, keepReports *bool) {
-	if defaultCoverageThreshold != nil && *defaultCoverageThreshold != 0 {
-		config.DefaultCoverageThreshold = *defaultCoverageThreshold
+func OverrideWithFlags(config *Config, flags Flags) {
+	if flags.DefaultCoverageThreshold != nil && *flags.DefaultCoverageThreshold != 0 {
+		config.DefaultCoverageThreshold = *flags.DefaultCoverageThreshold
+	}
+	if flags.CoverageReportsDir != nil && *flags.CoverageReportsDir != "" {
+		config.CoverageReportsDir = *flags.CoverageReportsDir
+	}
+	if flags.CoverageReportsFormat != nil && *flags.CoverageReportsFormat != "" {
+		config.CoverageReportsFormat = *flags.CoverageReportsFormat
+	}
+	if flags.LogLevel != nil && *flags.LogLevel != "" {
+		config.Logging.Level = *flags.LogLevel
+	}
+	if flags.LogFile != nil && *flags.LogFile != "" {
+		config.Logging.File = *flags.LogFile
+	}
+	if flags.ExcludePatterns != nil && *flags.ExcludePatterns != "" {
+		config.ExcludePackages = append(config.ExcludePackages, strings.Split(*flags.ExcludePatterns, ",")...)
+	}
+	if flags.KeepReports != nil {
+		config.KeepReports = *flags.KeepReports
+	}
+	if flags.Parallelism != nil && *flags.Parallelism > 0 {
+		config.Parallelism = *flags.Parallelism
+	}
+	if len(flags.Outputs) > 0 {
+		config.Output = flags.Outputs
+	}
+	if flags.Detail != nil && *flags.Detail {
+		config.Detail = true
+	}
+	if flags.Base != nil && *flags.Base != "" {
+		config.Base = *flags.Base
 	}
-	if coverageReportsDir != nil && *coverageReportsDir != "" {
-		config.CoverageReportsDir = *coverageReportsDir
+	if flags.PatchThreshold != nil && *flags.PatchThreshold != 0 {
+		config.PatchThreshold = *flags.PatchThreshold
 	}
-	if coverageReportsFormat != nil && *coverageReportsFormat != "" {
-		config.CoverageReportsFormat = *coverageReportsFormat
+	if flags.ChangedSince != nil && *flags.ChangedSince != "" {
+		config.ChangedSince = *flags.ChangedSince
 	}
-	if logLevel != nil && *logLevel != "" {
-		config.Logging.Level = *logLevel
+	if flags.Tidy != nil && *flags.Tidy {
+		config.Tidy = true
 	}
-	if logFile != nil && *logFile != "" {
-		config.Logging.File = *logFile
+	if flags.UntestedPolicy != nil && *flags.UntestedPolicy != "" {
+		config.UntestedPackages.Policy = *flags.UntestedPolicy
 	}
-	if excludePatterns != nil && *excludePatterns != "" {
-		config.ExcludePackages = append(config.ExcludePackages, strings.Split(*excludePatterns, ",")...)
+	if flags.CoverPkg != nil && *flags.CoverPkg != "" {
+		config.CoverPkg = strings.Split(*flags.CoverPkg, ",")
 	}
-	if keepReports != nil {
-		config.KeepReports = *keepReports
+	if flags.GlobalThreshold != nil && *flags.GlobalThreshold != 0 {
+		config.GlobalThreshold = *flags.GlobalThreshold
+	}
+	if flags.BaselineFile != nil && *flags.BaselineFile != "" {
+		config.Baseline.File = *flags.BaselineFile
+	}
+	if flags.BaselineTolerance != nil && *flags.BaselineTolerance != 0 {
+		config.Baseline.Tolerance = *flags.BaselineTolerance
+	}
+	if flags.UpdateBaseline != nil && *flags.UpdateBaseline {
+		config.Baseline.Update = true
+	}
+	if flags.History != nil && *flags.History {
+		config.History = true
+	}
+	if flags.Delta != nil && *flags.Delta {
+		config.Delta = true
+	}
+	if flags.BadgeDir != nil && *flags.BadgeDir != "" {
+		config.BadgeDir = *flags.BadgeDir
+	}
+	if flags.JUnitTests != nil && *flags.JUnitTests {
+		config.JUnitTests = true
+	}
+	if len(flags.SubtreeThresholds) > 0 {
+		if config.SubtreeThresholds == nil {
+			config.SubtreeThresholds = make(map[string]float64)
+		}
+		for pattern, threshold := range flags.SubtreeThresholds {
+			config.SubtreeThresholds[pattern] = threshold
+		}
 	}
 }
 
@@ -137,11 +434,31 @@ func ExtractFinalConfig() (Config, error) {
 	configFilePath := flag.String("config", "", "Path to the configuration file")
 	defaultCoverageThreshold := flag.Float64("default-threshold", 0, "Default coverage threshold")
 	coverageReportsDir := flag.String("coverage-dir", "", "Directory for coverage reports")
-	coverageReportsFormat := flag.String("coverage-reports-format", "", "Output format for coverage reports (out or lcov)")
+	coverageReportsFormat := flag.String("coverage-reports-format", "", "Output format for coverage reports (out, lcov or cobertura)")
 	logLevel := flag.String("log-level", "", "Log level (debug, info, warn, error)")
 	logFile := flag.String("log-file", "", "Log file (default: log to stdout)")
 	keepReports := flag.Bool("keep-reports", true, "Keep coverage reports after printing (default: true)")
 	excludePatterns := flag.String("exclude", "", "Comma-separated list of package patterns to exclude")
+	parallelism := flag.Int("jobs", 0, "Number of packages to test concurrently (default: number of CPUs)")
+	detail := flag.Bool("detail", false, "List the least-covered functions of each failing package")
+	base := flag.String("base", "", "Git ref to compute patch coverage of changed lines against (e.g. origin/main)")
+	patchThreshold := flag.Float64("patch-threshold", 0, "Patch coverage threshold for changed lines")
+	changedSince := flag.String("changed-since", "", "Only test packages affected by changes since this git ref")
+	tidy := flag.Bool("tidy", false, "Run 'go mod tidy' in the target directory before listing packages (modifies go.mod and go.sum)")
+	untestedPolicy := flag.String("untested", "", "Policy for packages without test files: fail, ignore or threshold (default: fail)")
+	coverPkg := flag.String("coverpkg", "", "Comma-separated list of package patterns to instrument in every test run (cross-package coverage)")
+	globalThreshold := flag.Float64("global-threshold", 0, "Aggregate coverage threshold for all packages together (default: disabled)")
+	baselineFile := flag.String("baseline-file", "", "Coverage baseline file (default: .coverco-baseline.json)")
+	baselineTolerance := flag.Float64("baseline-tolerance", 0, "Percentage points a package may drop below its baseline")
+	updateBaseline := flag.Bool("update", false, "Raise the baseline of packages whose coverage improved")
+	history := flag.Bool("history", false, "Append this run to the coverage history in the reports directory")
+	delta := flag.Bool("delta", false, "Show the change in coverage since the previous recorded run")
+	badgeDir := flag.String("badge-dir", "", "Directory to write an SVG coverage badge per package to (badge output)")
+	junitTests := flag.Bool("junit-tests", false, "Run tests with go test -json and include the individual go test results in the junit output")
+	subtreeThresholds := make(subtreeThresholdFlag)
+	flag.Var(subtreeThresholds, "subtree-threshold", "Aggregate threshold of an import path subtree as pattern=percent (e.g. internal/...=75), repeatable or comma-separated")
+	var outputs outputFlag
+	flag.Var(&outputs, "output", "Output format=path pairs, repeatable or comma-separated; \"-\" is stdout (default: table=-)")
 
 	flag.Parse()
 
@@ -157,7 +474,33 @@ func ExtractFinalConfig() (Config, error) {
 	}
 
 	// Override config with flags if they are set
-	OverrideWithFlags(&config, defaultCoverageThreshold, excludePatterns, coverageReportsDir, coverageReportsFormat, logLevel, logFile, keepReports)
+	OverrideWithFlags(&config, Flags{
+		DefaultCoverageThreshold: defaultCoverageThreshold,
+		ExcludePatterns:          excludePatterns,
+		CoverageReportsDir:       coverageReportsDir,
+		CoverageReportsFormat:    coverageReportsFormat,
+		LogLevel:                 logLevel,
+		LogFile:                  logFile,
+		KeepReports:              keepReports,
+		Parallelism:              parallelism,
+		Outputs:                  outputs,
+		Detail:                   detail,
+		Base:                     base,
+		PatchThreshold:           patchThreshold,
+		ChangedSince:             changedSince,
+		Tidy:                     tidy,
+		UntestedPolicy:           untestedPolicy,
+		CoverPkg:                 coverPkg,
+		GlobalThreshold:          globalThreshold,
+		SubtreeThresholds:        subtreeThresholds,
+		BaselineFile:             baselineFile,
+		BaselineTolerance:        baselineTolerance,
+		UpdateBaseline:           updateBaseline,
+		History:                  history,
+		Delta:                    delta,
+		BadgeDir:                 badgeDir,
+		JUnitTests:               junitTests,
+	})
 
 	return config, nil
 }
@@ -0,0 +1,53 @@
+package conf
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputTarget(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected OutputTarget
+		wantErr  bool
+	}{
+		{value: "json=cov.json", expected: OutputTarget{Format: "json", Path: "cov.json"}},
+		{value: " csv = out/cov.csv ", expected: OutputTarget{Format: "csv", Path: "out/cov.csv"}},
+		{value: "table", expected: OutputTarget{Format: "table", Path: DefaultOutputPath}},
+		{value: "table=", expected: OutputTarget{Format: "table", Path: DefaultOutputPath}},
+		{value: "junit=a=b.xml", expected: OutputTarget{Format: "junit", Path: "a=b.xml"}},
+		{value: "=cov.json", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		target, err := ParseOutputTarget(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, target, tt.value)
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	var outputs outputFlag
+	flags := flag.NewFlagSet("coverco", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(&outputs, "output", "")
+
+	require.NoError(t, flags.Parse([]string{"-output", "table=-,json=cov.json", "-output", "junit=junit.xml"}))
+	assert.Equal(t, outputFlag{
+		{Format: "table", Path: "-"},
+		{Format: "json", Path: "cov.json"},
+		{Format: "junit", Path: "junit.xml"},
+	}, outputs)
+	assert.Equal(t, "table=-,json=cov.json,junit=junit.xml", outputs.String())
+
+	assert.Error(t, flags.Parse([]string{"-output", "table,=cov.json"}))
+}
@@ -0,0 +1,171 @@
+package finder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// listedPackage is a package of the import graph reported by go list -deps -test
+type listedPackage struct {
+	importPath string
+	dir        string
+	deps       []string
+}
+
+// AffectedPackages returns the import paths of the packages in folder whose
+// files, or whose transitive in-module dependencies (including test
+// dependencies), changed since the merge base of ref and HEAD. Uncommitted
+// and untracked files count as changes. A change to go.mod or go.sum affects
+// every package.
+func AffectedPackages(folder, ref string) (map[string]bool, error) {
+	rootDir, err := gitOutput(folder, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("error finding git repository of %s: %w", folder, err)
+	}
+	mergeBase, err := gitOutput(folder, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("error finding merge base with %s: %w", ref, err)
+	}
+	changed, err := gitOutput(rootDir, "diff", "--name-only", "--no-renames", mergeBase)
+	if err != nil {
+		return nil, fmt.Errorf("error listing files changed since %s: %w", ref, err)
+	}
+	untracked, err := gitOutput(rootDir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("error listing untracked files: %w", err)
+	}
+
+	graph, err := listImportGraph(folder)
+	if err != nil {
+		return nil, err
+	}
+	return affectedPackages(rootDir, strings.Fields(changed+"\n"+untracked), graph), nil
+}
+
+// affectedPackages returns the non-test packages of the graph that own one of
+// the changed files, given relative to rootDir, or depend on such a package
+func affectedPackages(rootDir string, files []string, graph map[string]listedPackage) map[string]bool {
+	pkgDirs := make(map[string]string)
+	for _, pkg := range graph {
+		if !pkg.isTestVariant() {
+			pkgDirs[pkg.dir] = pkg.importPath
+		}
+	}
+
+	// Attribute each changed file to the package in its nearest enclosing directory
+	changedPkgs := make(map[string]bool)
+	everything := false
+	for _, file := range files {
+		path := filepath.Join(rootDir, filepath.FromSlash(file))
+		if base := filepath.Base(path); base == "go.mod" || base == "go.sum" {
+			log.Infof("%s changed, all packages are affected", file)
+			everything = true
+			break
+		}
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if importPath, ok := pkgDirs[dir]; ok {
+				changedPkgs[importPath] = true
+				break
+			}
+			if dir == rootDir || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+
+	affected := make(map[string]bool)
+	for _, pkg := range graph {
+		if pkg.isTestVariant() {
+			continue
+		}
+		if everything || changedPkgs[pkg.importPath] {
+			affected[pkg.importPath] = true
+			continue
+		}
+		for _, dep := range graph[pkg.importPath+".test"].depsOr(pkg.deps) {
+			if changedPkgs[dep] {
+				log.Debugf("Package %s is affected by changes in %s", pkg.importPath, dep)
+				affected[pkg.importPath] = true
+				break
+			}
+		}
+	}
+	return affected
+}
+
+// listImportGraph lists the packages in folder with their transitive
+// dependencies, including the dependencies of their test binaries
+func listImportGraph(folder string) (map[string]listedPackage, error) {
+	cmd := exec.Command("go", "list", "-deps", "-test", "-f", `{{.ImportPath}}{{"\t"}}{{.Dir}}{{"\t"}}{{join .Deps "\t"}}`, "./...")
+	cmd.Dir = folder
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing import graph in folder %s: %w", folder, err)
+	}
+	return parseImportGraph(output)
+}
+
+// parseImportGraph parses the tab-separated import path, directory and
+// dependencies listed for every package. Tabs separate the dependencies too,
+// since test variants such as "p [p.test]" contain spaces.
+func parseImportGraph(output []byte) (map[string]listedPackage, error) {
+	graph := make(map[string]listedPackage)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		pkg := listedPackage{importPath: fields[0], dir: fields[1]}
+		for _, dep := range fields[2:] {
+			if dep == "" {
+				continue
+			}
+			pkg.deps = append(pkg.deps, stripTestVariant(dep))
+		}
+		graph[pkg.importPath] = pkg
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// isTestVariant reports whether the package is a test variant ("p [p.test]") or test main ("p.test")
+func (p listedPackage) isTestVariant() bool {
+	return strings.HasSuffix(p.importPath, "]") || strings.HasSuffix(p.importPath, ".test")
+}
+
+// depsOr returns the dependencies of p, or fallback when p is not listed
+func (p listedPackage) depsOr(fallback []string) []string {
+	if p.importPath == "" {
+		return fallback
+	}
+	return p.deps
+}
+
+// stripTestVariant removes the " [p.test]" suffix of a test variant import path
+func stripTestVariant(importPath string) string {
+	if i := strings.Index(importPath, " ["); i >= 0 {
+		return importPath[:i]
+	}
+	return importPath
+}
+
+// gitOutput runs git with the given arguments in dir and returns its trimmed output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,95 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importGraph is go list -deps -test output for a module where b imports a,
+// and the tests of c import b
+const importGraph = "example.com/m/a\t/src/m/a\t\n" +
+	"example.com/m/b\t/src/m/b\texample.com/m/a\n" +
+	"example.com/m/c\t/src/m/c\t\n" +
+	"example.com/m/c [example.com/m/c.test]\t/src/m/c\texample.com/m/a\texample.com/m/b\n" +
+	"example.com/m/c.test\t/src/m/c\texample.com/m/a\texample.com/m/b\texample.com/m/c [example.com/m/c.test]\n" +
+	"example.com/m/d\t/src/m/d\t\n"
+
+func TestParseImportGraph(t *testing.T) {
+	graph, err := parseImportGraph([]byte(importGraph))
+	require.NoError(t, err)
+	assert.Len(t, graph, 6)
+	assert.Equal(t, listedPackage{importPath: "example.com/m/b", dir: "/src/m/b", deps: []string{"example.com/m/a"}}, graph["example.com/m/b"])
+	assert.Equal(t, []string{"example.com/m/a", "example.com/m/b", "example.com/m/c"}, graph["example.com/m/c.test"].deps)
+	assert.True(t, graph["example.com/m/c [example.com/m/c.test]"].isTestVariant())
+	assert.False(t, graph["example.com/m/c"].isTestVariant())
+}
+
+func TestListImportGraph(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	files := map[string]string{
+		"go.mod":        "module example.com/m\n\ngo 1.22\n",
+		"a/a.go":        "package a\n",
+		"b/b.go":        "package b\n",
+		"b/b_test.go":   "package b\n\nimport _ \"example.com/m/a\"\n",
+		"c/c_x_test.go": "package c_test\n\nimport _ \"example.com/m/b\"\n",
+		"c/c.go":        "package c\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	graph, err := listImportGraph(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "b"), graph["example.com/m/b"].dir)
+	assert.Contains(t, graph["example.com/m/b.test"].deps, "example.com/m/a")
+	assert.Contains(t, graph["example.com/m/c.test"].deps, "example.com/m/b")
+	assert.NotContains(t, graph["example.com/m/c"].deps, "example.com/m/b")
+
+	affected := affectedPackages(dir, []string{"a/a.go"}, graph)
+	assert.Equal(t, map[string]bool{"example.com/m/a": true, "example.com/m/b": true}, affected)
+}
+
+func TestAffectedPackages(t *testing.T) {
+	graph, err := parseImportGraph([]byte(importGraph))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"dependency changed", []string{"a/a.go"}, []string{"example.com/m/a", "example.com/m/b", "example.com/m/c"}},
+		{"test file changed", []string{"c/c_test.go"}, []string{"example.com/m/c"}},
+		{"file in nested directory", []string{"d/testdata/golden.txt"}, []string{"example.com/m/d"}},
+		{"file outside packages", []string{"README.md"}, nil},
+		{"go.mod changed", []string{"d/d.go", "go.mod"}, []string{"example.com/m/a", "example.com/m/b", "example.com/m/c", "example.com/m/d"}},
+		{"go.sum changed", []string{"go.sum"}, []string{"example.com/m/a", "example.com/m/b", "example.com/m/c", "example.com/m/d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for pkg := range affectedPackages("/src/m", tt.files, graph) {
+				got = append(got, pkg)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestStripTestVariant(t *testing.T) {
+	tests := map[string]string{
+		"example.com/m/a":                             "example.com/m/a",
+		"example.com/m/a [example.com/m/a.test]":      "example.com/m/a",
+		"example.com/m/a_test [example.com/m/a.test]": "example.com/m/a_test",
+		"example.com/m/a.test":                        "example.com/m/a.test",
+	}
+	for importPath, want := range tests {
+		assert.Equal(t, want, stripTestVariant(importPath), importPath)
+	}
+}
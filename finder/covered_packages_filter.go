@@ -1,6 +1,8 @@
 package finder
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,9 +14,28 @@ import (
 	"github.com/charmbracelet/log"
 )
 
+// Package is a Go package selected for coverage along with the metadata reported by go list
 type Package struct {
 	Name      string
 	Threshold float64
+	Pattern   string
+
+	Dir          string
+	Module       string
+	GoFiles      []string // non-test Go source files, including cgo files
+	TestGoFiles  []string // _test.go files in the package
+	XTestGoFiles []string // _test.go files outside the package (package foo_test)
+	IsMain       bool
+}
+
+// HasGoFiles reports whether the package has non-test Go source files
+func (p Package) HasGoFiles() bool {
+	return len(p.GoFiles) > 0
+}
+
+// HasTests reports whether the package has any test files
+func (p Package) HasTests() bool {
+	return len(p.TestGoFiles) > 0 || len(p.XTestGoFiles) > 0
 }
 
 // PatternMatchError provides detailed information about pattern matching errors.
@@ -34,44 +55,96 @@ func (e *PatternMatchError) Unwrap() error {
 // PackageFilter manages the filtering of packages based on configuration.
 type packageFilter struct {
 	config       conf.Config
-	allPackages  []string
+	allPackages  []Package
 	matchedPkgs  []Package
 	excludedPkgs []Package
 }
 
 // NewPackageFilter creates a new PackageFilter instance.
-func newPackageFilter(config conf.Config, allPackages []string) *packageFilter {
+func newPackageFilter(config conf.Config, allPackages []Package) *packageFilter {
 	return &packageFilter{
 		config:      config,
 		allPackages: allPackages,
 	}
 }
 
-// MatchPackages matches packages based on the cover patterns specified in the configuration.
+// MatchPackages resolves each package against the cover patterns specified in the configuration.
+// A package matched by several patterns is resolved once, using the pattern that wins in patternRank.
 func (pf *packageFilter) matchPackages() error {
-	for _, coverPattern := range pf.config.CoverPackages {
-		found := false
-		for _, pkg := range pf.allPackages {
-			matched, err := matchPattern(pkg, []string{coverPattern.Name})
+	found := make([]bool, len(pf.config.CoverPackages))
+	for _, pkg := range pf.allPackages {
+		best := -1
+		var candidates []string
+		for i, coverPattern := range pf.config.CoverPackages {
+			matched, err := matchPattern(pkg.Name, []string{coverPattern.Name})
 			if err != nil {
 				return err
 			}
-			if matched {
-				threshold := pf.config.DefaultCoverageThreshold
-				if coverPattern.Threshold != nil {
-					threshold = *coverPattern.Threshold
-				}
-				pf.matchedPkgs = append(pf.matchedPkgs, Package{Name: pkg, Threshold: threshold})
-				found = true
+			if !matched {
+				continue
 			}
+			found[i] = true
+			candidates = append(candidates, coverPattern.Name)
+			if best < 0 || pf.outranks(i, best) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		coverPattern := pf.config.CoverPackages[best]
+		threshold := pf.config.DefaultCoverageThreshold
+		if coverPattern.Threshold != nil {
+			threshold = *coverPattern.Threshold
 		}
-		if !found {
+		if len(candidates) > 1 {
+			log.Debugf("Package %s matches patterns %v; using %q (priority %d, specificity %d) with threshold %.2f%%",
+				pkg.Name, candidates, coverPattern.Name, priority(coverPattern.Priority), patternSpecificity(coverPattern.Name), threshold)
+		}
+		pkg.Threshold = threshold
+		pkg.Pattern = coverPattern.Name
+		pf.matchedPkgs = append(pf.matchedPkgs, pkg)
+	}
+
+	for i, coverPattern := range pf.config.CoverPackages {
+		if !found[i] {
 			log.Warnf("No packages found matching cover pattern: %s", coverPattern.Name)
 		}
 	}
 	return nil
 }
 
+// outranks reports whether cover pattern i takes precedence over cover pattern j.
+// An explicit higher priority wins first, then the more specific pattern; on a
+// tie the pattern listed first in the configuration wins.
+func (pf *packageFilter) outranks(i, j int) bool {
+	a, b := pf.config.CoverPackages[i], pf.config.CoverPackages[j]
+	if pa, pb := priority(a.Priority), priority(b.Priority); pa != pb {
+		return pa > pb
+	}
+	if sa, sb := patternSpecificity(a.Name), patternSpecificity(b.Name); sa != sb {
+		return sa > sb
+	}
+	return i < j
+}
+
+// priority returns the configured priority of a cover pattern, defaulting to zero
+func priority(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// patternSpecificity scores how specific a pattern is: every literal character
+// counts, and wildcards count against the pattern.
+func patternSpecificity(pattern string) int {
+	wildcards := strings.Count(pattern, "*")
+	literals := len(pattern) - wildcards
+	return literals - wildcards
+}
+
 // ExcludePackages excludes packages based on the patterns specified in the configuration.
 func (pf *packageFilter) excludePackages() error {
 	for _, pkg := range pf.matchedPkgs {
@@ -107,12 +180,12 @@ func (pf *packageFilter) getFilteredPackages() []Package {
 func FilterCoveredPackages(cfg conf.Config, dirPath string) ([]Package, error) {
 
 	// List Go packages in the specified folder
-	allPackages, err := ListGoPackages(dirPath)
+	allPackages, err := ListGoPackages(dirPath, cfg.Tidy)
 	if err != nil {
 		return nil, fmt.Errorf("error listing packages: %w", err)
 	}
 
-	pf := newPackageFilter(cfg, allPackages)
+	pf := newPackageFilter(cfg, coverablePackages(allPackages, len(cfg.CoverPkg) > 0))
 
 	if err := pf.matchPackages(); err != nil {
 		return nil, fmt.Errorf("error matching packages: %w", err)
@@ -122,7 +195,67 @@ func FilterCoveredPackages(cfg conf.Config, dirPath string) ([]Package, error) {
 		return nil, fmt.Errorf("error excluding packages: %w", err)
 	}
 
-	return pf.getFilteredPackages(), nil
+	filteredPackages := pf.getFilteredPackages()
+	if cfg.ChangedSince == "" {
+		return filteredPackages, nil
+	}
+
+	affected, err := AffectedPackages(dirPath, cfg.ChangedSince)
+	if err != nil {
+		return nil, fmt.Errorf("error finding packages changed since %s: %w", cfg.ChangedSince, err)
+	}
+
+	var changedPackages []Package
+	for _, pkg := range filteredPackages {
+		if affected[pkg.Name] {
+			changedPackages = append(changedPackages, pkg)
+		} else {
+			log.Debugf("Skipping package not affected by changes since %s: %s", cfg.ChangedSince, pkg.Name)
+		}
+	}
+	log.Infof("Testing %d of %d packages affected by changes since %s", len(changedPackages), len(filteredPackages), cfg.ChangedSince)
+	return changedPackages, nil
+}
+
+// coverablePackages drops the packages without non-test Go files, which have
+// no statements to cover. In cross-package mode, packages made only of test
+// files are kept, since their tests cover the packages matched by coverpkg.
+func coverablePackages(packages []Package, crossPackage bool) []Package {
+	var coverable []Package
+	for _, pkg := range packages {
+		if !pkg.HasGoFiles() && !(crossPackage && pkg.HasTests()) {
+			log.Infof("Skipping package without Go files: %s", pkg.Name)
+			continue
+		}
+		coverable = append(coverable, pkg)
+	}
+	return coverable
+}
+
+// ResolveCoverPkg returns the names of the packages matching the coverpkg patterns,
+// for use with go test -coverpkg. Packages without non-test Go files are left out.
+func ResolveCoverPkg(packages []Package, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	var coverPkg []string
+	for _, pkg := range packages {
+		if !pkg.HasGoFiles() {
+			continue
+		}
+		matched, err := matchPattern(pkg.Name, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			coverPkg = append(coverPkg, pkg.Name)
+		}
+	}
+	if len(coverPkg) == 0 {
+		log.Warnf("No packages found matching coverpkg patterns: %v", patterns)
+	}
+	return coverPkg, nil
 }
 
 // Supported Patterns:
@@ -144,29 +277,102 @@ func matchPattern(packageName string, patterns []string) (bool, error) {
 	return false, nil
 }
 
-// listGoPackages lists all Go packages in the specified folder and its subdirectories
-func ListGoPackages(folder string) ([]string, error) {
-	// Run go mod tidy first
-	err := runGoModTidy()
+// goListPackage holds the fields of `go list -json` output used by coverco
+type goListPackage struct {
+	ImportPath   string
+	Dir          string
+	Name         string
+	Module       *struct{ Path string }
+	GoFiles      []string
+	CgoFiles     []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Error        *goListError
+	DepsErrors   []*goListError
+}
+
+// goListError is a package loading error reported by `go list -e -json`
+type goListError struct {
+	Pos string
+	Err string
+}
+
+// ListGoPackages lists all Go packages in the specified folder and its subdirectories.
+// Discovery is read-only unless tidy is set, in which case 'go mod tidy' is run in the folder first.
+// The returned packages carry the metadata reported by go list but no threshold.
+func ListGoPackages(folder string, tidy bool) ([]Package, error) {
+	if tidy {
+		err := runGoModTidy(folder)
+		if err != nil {
+			return nil, fmt.Errorf("error running go mod tidy: %w", err)
+		}
+	}
+
+	listed, err := goList(folder)
 	if err != nil {
-		return nil, fmt.Errorf("error running go mod tidy: %w", err)
+		return nil, err
+	}
+
+	packages := make([]Package, 0, len(listed))
+	for _, pkg := range listed {
+		p := Package{
+			Name:         pkg.ImportPath,
+			Dir:          pkg.Dir,
+			GoFiles:      append(pkg.GoFiles, pkg.CgoFiles...),
+			TestGoFiles:  pkg.TestGoFiles,
+			XTestGoFiles: pkg.XTestGoFiles,
+			IsMain:       pkg.Name == "main",
+		}
+		if pkg.Module != nil {
+			p.Module = pkg.Module.Path
+		}
+		packages = append(packages, p)
 	}
+	return packages, nil
+}
 
-	cmd := exec.Command("go", "list", "./...")
+// goList runs `go list -e -json ./...` in the folder and decodes the packages.
+// Package loading errors, such as missing modules, are collected into a single error.
+func goList(folder string) ([]goListPackage, error) {
+	cmd := exec.Command("go", "list", "-e", "-json", "./...")
 	cmd.Dir = folder
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("error listing Go packages in folder %s: %w", folder, err)
+		return nil, fmt.Errorf("error listing Go packages in folder %s: %w: %s", folder, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []goListPackage
+	var loadErrors []string
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var pkg goListPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("error decoding go list output: %w", err)
+		}
+		if pkg.Error != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %s", pkg.ImportPath, pkg.Error.Err))
+		}
+		for _, depErr := range pkg.DepsErrors {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: dependency: %s", pkg.ImportPath, depErr.Err))
+		}
+		packages = append(packages, pkg)
+	}
+
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("error loading Go packages in folder %s (go.mod or go.sum may need updating; rerun with -tidy to run 'go mod tidy'):\n  %s",
+			folder, strings.Join(loadErrors, "\n  "))
 	}
-	packages := strings.Fields(string(output))
 	return packages, nil
 }
 
-// runGoModTidy runs 'go mod tidy' in the current directory
-func runGoModTidy() error {
-	log.Info("Running go mod tidy...")
+// runGoModTidy runs 'go mod tidy' in the specified folder
+func runGoModTidy(folder string) error {
+	log.Infof("Running go mod tidy in %s...", folder)
 
 	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = folder
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	err := cmd.Run()
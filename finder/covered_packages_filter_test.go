@@ -0,0 +1,147 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPackagesMostSpecificPattern(t *testing.T) {
+	high := 1
+	cfg := conf.GetDefaultConfig()
+	cfg.CoverPackages = []struct {
+		Name      string   `yaml:"name"`
+		Threshold *float64 `yaml:"threshold,omitempty"`
+		Priority  *int     `yaml:"priority,omitempty"`
+	}{
+		{Name: "*", Threshold: ptr(80.0)},
+		{Name: "demo/services/*", Threshold: ptr(90.0)},
+		{Name: "demo/arrays", Threshold: ptr(95.0)},
+		{Name: "demo/legacy/*", Threshold: ptr(50.0)},
+		{Name: "*/legacy/*", Threshold: ptr(10.0), Priority: &high},
+	}
+
+	pf := newPackageFilter(cfg, []Package{{Name: "demo/arrays"}, {Name: "demo/legacy/old"}, {Name: "demo/services/api"}, {Name: "demo/utility"}})
+	require.NoError(t, pf.matchPackages())
+
+	assert.Equal(t, []Package{
+		{Name: "demo/arrays", Threshold: 95.0, Pattern: "demo/arrays"},
+		{Name: "demo/legacy/old", Threshold: 10.0, Pattern: "*/legacy/*"},
+		{Name: "demo/services/api", Threshold: 90.0, Pattern: "demo/services/*"},
+		{Name: "demo/utility", Threshold: 80.0, Pattern: "*"},
+	}, pf.matchedPkgs)
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+func TestCoverablePackages(t *testing.T) {
+	packages := []Package{
+		{Name: "example.com/cv/lib", GoFiles: []string{"lib.go"}},
+		{Name: "example.com/cv/e2e", XTestGoFiles: []string{"e2e_test.go"}},
+		{Name: "example.com/cv/empty"},
+	}
+
+	assert.Equal(t, packages[:1], coverablePackages(packages, false))
+	assert.Equal(t, packages[:2], coverablePackages(packages, true))
+}
+
+func TestResolveCoverPkg(t *testing.T) {
+	packages := []Package{
+		{Name: "example.com/cv/lib", GoFiles: []string{"lib.go"}},
+		{Name: "example.com/cv/lib/internal", GoFiles: []string{"internal.go"}},
+		{Name: "example.com/cv/e2e", XTestGoFiles: []string{"e2e_test.go"}},
+		{Name: "example.com/cv/cmd", GoFiles: []string{"main.go"}},
+	}
+
+	coverPkg, err := ResolveCoverPkg(packages, []string{"example.com/cv/lib", "example.com/cv/lib/*", "example.com/cv/e2e"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/cv/lib", "example.com/cv/lib/internal"}, coverPkg)
+
+	coverPkg, err = ResolveCoverPkg(packages, nil)
+	require.NoError(t, err)
+	assert.Nil(t, coverPkg)
+}
+
+// writeModule writes the files of a module into a temporary directory and returns it
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestListGoPackages(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":                "module example.com/m\n\ngo 1.22\n",
+		"lib/lib.go":            "package lib\n",
+		"lib/lib_test.go":       "package lib\n",
+		"lib/lib_x_test.go":     "package lib_test\n",
+		"cmd/tool/main.go":      "package main\n\nimport _ \"example.com/m/lib\"\n\nfunc main() {}\n",
+		"e2e/e2e_test.go":       "package e2e\n",
+		"internal/util/util.go": "package util\n",
+	})
+
+	packages, err := ListGoPackages(dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{Name: "example.com/m/cmd/tool", Dir: filepath.Join(dir, "cmd/tool"), Module: "example.com/m", GoFiles: []string{"main.go"}, IsMain: true},
+		{Name: "example.com/m/e2e", Dir: filepath.Join(dir, "e2e"), Module: "example.com/m", TestGoFiles: []string{"e2e_test.go"}},
+		{Name: "example.com/m/internal/util", Dir: filepath.Join(dir, "internal/util"), Module: "example.com/m", GoFiles: []string{"util.go"}},
+		{Name: "example.com/m/lib", Dir: filepath.Join(dir, "lib"), Module: "example.com/m", GoFiles: []string{"lib.go"}, TestGoFiles: []string{"lib_test.go"}, XTestGoFiles: []string{"lib_x_test.go"}},
+	}, packages)
+}
+
+func TestGoListLoadErrors(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":     "module example.com/m\n\ngo 1.22\n",
+		"ok/ok.go":   "package ok\n",
+		"bad/bad.go": "package bad\n\nimport _ \"example.com/m/missing\"\n",
+	})
+
+	_, err := goList(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "example.com/m/bad: ")
+	assert.Contains(t, err.Error(), "rerun with -tidy")
+	assert.NotContains(t, err.Error(), "example.com/m/ok")
+
+	_, err = goList(filepath.Join(dir, "nonexistent"))
+	assert.ErrorContains(t, err, "error listing Go packages")
+}
+
+func TestListGoPackagesTidy(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	goMod := "module example.com/m\n\ngo 1.22\n\nreplace example.com/dep => ./dep\n"
+	dir := writeModule(t, map[string]string{
+		"go.mod":     goMod,
+		"app/app.go": "package app\n\nimport _ \"example.com/dep\"\n",
+		"dep/go.mod": "module example.com/dep\n\ngo 1.22\n",
+		"dep/dep.go": "package dep\n",
+	})
+
+	_, err := ListGoPackages(dir, false)
+	require.Error(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, goMod, string(content), "discovery without tidy must not modify go.mod")
+
+	packages, err := ListGoPackages(dir, true)
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	assert.Equal(t, "example.com/m/app", packages[0].Name)
+	content, err = os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "require example.com/dep")
+
+	assert.Error(t, runGoModTidy(filepath.Join(dir, "nonexistent")))
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/mkabdelrahman/coverco/conf"
 	"github.com/mkabdelrahman/coverco/finder"
@@ -14,14 +17,37 @@ import (
 	"github.com/charmbracelet/log"
 )
 
+// Exit codes reported to the calling shell or CI pipeline
+const (
+	ExitOK                = 0
+	ExitCoverageTooLow    = 1
+	ExitTestsFailed       = 2
+	ExitConfigOrToolError = 3
+)
+
+// Subcommands selected by the first argument
+const (
+	// CommandBaseline records the current coverage as the baseline
+	CommandBaseline = "baseline"
+	// CommandHistory prints the coverage trends of the recorded runs
+	CommandHistory = "history"
+)
+
 func main() {
+	os.Exit(run())
+}
+
+// run executes coverco and returns the process exit code
+func run() int {
 	log.SetLevel(log.DebugLevel)
 
+	command := subcommand()
+
 	// Extract final configuration
 	config, err := conf.ExtractFinalConfig()
 	if err != nil {
 		log.Errorf("Error extracting final config: %s", err.Error())
-		return
+		return ExitConfigOrToolError
 	}
 
 	// The first non-flag argument is the directory path
@@ -36,28 +62,102 @@ func main() {
 	err = setupLogging(config)
 	if err != nil {
 		log.Errorf("Error setting up logging: %s", err.Error())
-		return
+		return ExitConfigOrToolError
+	}
+
+	if command == CommandHistory {
+		return printHistory(config)
 	}
 
 	packages, err := finder.FilterCoveredPackages(config, dirPath)
 	if err != nil {
-		fmt.Printf("Failed to create packages list: %v\n", err)
-		return
+		log.Errorf("Failed to create packages list: %v", err)
+		return ExitConfigOrToolError
 	}
 
-	reporter, err := reporter.NewCoverageReporter(packages, config.DefaultCoverageThreshold, config.CoverageReportsDir, config.CoverageReportsFormat)
+	// Coverpkg patterns match every package of the module, not only the
+	// packages selected for testing
+	var coverPkg []string
+	if len(config.CoverPkg) > 0 {
+		allPackages, err := finder.ListGoPackages(dirPath, false)
+		if err != nil {
+			log.Errorf("Failed to list packages: %v", err)
+			return ExitConfigOrToolError
+		}
+		coverPkg, err = finder.ResolveCoverPkg(allPackages, config.CoverPkg)
+		if err != nil {
+			log.Errorf("Failed to resolve coverpkg patterns: %v", err)
+			return ExitConfigOrToolError
+		}
+	}
+
+	reporter, err := reporter.NewCoverageReporter(packages, reporter.Options{
+		ProjectDir:               dirPath,
+		DefaultCoverageThreshold: config.DefaultCoverageThreshold,
+		ReportsDir:               config.CoverageReportsDir,
+		OutputFormat:             config.CoverageReportsFormat,
+		KeepReports:              config.KeepReports,
+		Parallelism:              config.Parallelism,
+		Base:                     config.Base,
+		PatchThreshold:           config.PatchThreshold,
+		UntestedPolicy:           config.UntestedPackages.Policy,
+		UntestedThreshold:        config.UntestedThreshold(),
+		CoverPkg:                 coverPkg,
+		GlobalThreshold:          config.GlobalThreshold,
+		SubtreeThresholds:        config.SubtreeThresholds,
+		BaselineFile:             config.Baseline.File,
+		BaselineTolerance:        config.Baseline.Tolerance,
+		RecordTests:              config.JUnitTests,
+	})
 	if err != nil {
 		log.Errorf("%s", err.Error())
-		return
+		return ExitConfigOrToolError
+	}
+
+	outputs, err := openOutputs(config.Output, reporter, printer.Options{Detail: config.Detail, Delta: config.Delta, BadgeDir: config.BadgeDir})
+	if err != nil {
+		log.Errorf("Error setting up outputs: %s", err.Error())
+		return ExitConfigOrToolError
 	}
 
 	coverages := reporter.TestPackages()
 
+	if config.History {
+		if err := reporter.RecordHistory(coverages); err != nil {
+			log.Errorf("Error recording coverage history: %s", err.Error())
+		}
+	}
+
 	// Print coverage results
-	printer := printer.NewCoveragePrinter(reporter, os.Stdout)
-	printer.PrintCoverageTable(coverages)
+	printFailed := false
+	for _, out := range outputs {
+		if err := out.printer.Print(coverages); err != nil {
+			log.Errorf("Error writing %s output to %s: %s", out.target.Format, out.target.Path, err.Error())
+			printFailed = true
+		}
+		if err := out.close(); err != nil {
+			log.Errorf("Error closing %s: %s", out.target.Path, err.Error())
+			printFailed = true
+		}
+	}
 	if !config.KeepReports {
-		err = os.RemoveAll(config.CoverageReportsDir)
+		// Keep the history, which outlives the reports of a single run, and
+		// the HTML report that was just announced
+		var keep []string
+		if config.History {
+			keep = append(keep, filepath.Base(reporter.HistoryFile()))
+		}
+		for _, target := range config.Output {
+			if target.Format == "html" {
+				keep = append(keep, printer.HTMLDirName)
+				break
+			}
+		}
+		if len(keep) > 0 {
+			err = removeReportsExcept(config.CoverageReportsDir, keep...)
+		} else {
+			err = os.RemoveAll(config.CoverageReportsDir)
+		}
 		if err != nil {
 			log.Errorf("Error removing coverage reports directory: %s", err.Error())
 		}
@@ -66,7 +166,7 @@ func main() {
 	if config.KeepReports && config.CoverageReportsFormat == "lcov" {
 		err = removeFilesWithExtension(config.CoverageReportsDir, ".out")
 		if err != nil {
-			log.Errorf("Error removing .lcov files: %s", err.Error())
+			log.Errorf("Error removing .out files: %s", err.Error())
 		}
 	}
 
@@ -77,6 +177,186 @@ func main() {
 		}
 	}
 
+	if config.KeepReports && config.CoverageReportsFormat == "cobertura" {
+		err = removeFilesWithExtension(config.CoverageReportsDir, ".out")
+		if err != nil {
+			log.Errorf("Error removing .out files: %s", err.Error())
+		}
+	}
+
+	if printFailed {
+		return ExitConfigOrToolError
+	}
+	if command == CommandBaseline {
+		return recordBaseline(reporter, coverages, config.Baseline.File)
+	}
+
+	code := exitCode(reporter, coverages)
+	if config.Baseline.Update && code != ExitTestsFailed {
+		if err := raiseBaseline(reporter, coverages, config.Baseline.File); err != nil {
+			log.Errorf("Error updating baseline: %s", err.Error())
+			return ExitConfigOrToolError
+		}
+	}
+	return code
+}
+
+// printHistory prints the coverage trends recorded in the reports directory
+func printHistory(config conf.Config) int {
+	entries, err := reporter.LoadHistory(filepath.Join(config.CoverageReportsDir, reporter.HistoryFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Errorf("Error loading coverage history: %s", err.Error())
+		return ExitConfigOrToolError
+	}
+	printer.PrintHistoryTrends(os.Stdout, entries, printer.HistoryTrendRuns)
+	return ExitOK
+}
+
+// subcommand removes a leading subcommand from the arguments and returns it,
+// or returns an empty string for a regular coverage run
+func subcommand() string {
+	if len(os.Args) > 1 && (os.Args[1] == CommandBaseline || os.Args[1] == CommandHistory) {
+		command := os.Args[1]
+		os.Args = append(os.Args[:1:1], os.Args[2:]...)
+		return command
+	}
+	return ""
+}
+
+// recordBaseline writes the coverage of all packages to the baseline file.
+// Nothing is recorded when tests failed, as their packages would be missing.
+func recordBaseline(cr *reporter.CoverageReporter, coverages []reporter.Coverage, fileName string) int {
+	for _, cov := range coverages {
+		if cov.Status.Failed() {
+			log.Errorf("Not recording baseline: tests failed for package %s: %s", cov.PackageName, cov.Status)
+			return ExitTestsFailed
+		}
+	}
+
+	baseline := cr.NewBaseline(coverages)
+	if err := baseline.Write(fileName); err != nil {
+		log.Errorf("Error recording baseline: %s", err.Error())
+		return ExitConfigOrToolError
+	}
+	log.Infof("Recorded coverage baseline of %d packages in %s (%d packages in total)", len(coverages), fileName, len(baseline.Packages))
+	return ExitOK
+}
+
+// raiseBaseline raises the baseline of packages whose coverage improved.
+// The baseline is left unchanged while any package is below it.
+func raiseBaseline(cr *reporter.CoverageReporter, coverages []reporter.Coverage, fileName string) error {
+	if len(cr.BaselineRegressions(coverages)) > 0 {
+		log.Warnf("Not updating baseline %s: coverage dropped", fileName)
+		return nil
+	}
+
+	baseline := cr.Baseline
+	if baseline == nil {
+		baseline = cr.NewBaseline(nil)
+	}
+	if !cr.Raise(baseline, coverages) {
+		log.Infof("Baseline %s is up to date", fileName)
+		return nil
+	}
+	if err := baseline.Write(fileName); err != nil {
+		return err
+	}
+	log.Infof("Raised coverage baseline in %s", fileName)
+	return nil
+}
+
+// output is a printer bound to the file it writes to
+type output struct {
+	target  conf.OutputTarget
+	printer printer.Printer
+	close   func() error
+}
+
+// openOutputs creates a printer for every configured output target, opening
+// its file for writing. A path of "-" writes to stdout.
+func openOutputs(targets []conf.OutputTarget, cr *reporter.CoverageReporter, opts printer.Options) ([]output, error) {
+	var outputs []output
+	closeAll := func() {
+		for _, out := range outputs {
+			out.close()
+		}
+	}
+
+	for _, target := range targets {
+		var w io.Writer = os.Stdout
+		closeFn := func() error { return nil }
+		if target.Path != "-" {
+			f, err := os.Create(target.Path)
+			if err != nil {
+				closeAll()
+				return nil, fmt.Errorf("error creating output file: %w", err)
+			}
+			w, closeFn = f, f.Close
+		}
+
+		p, err := printer.NewPrinter(target.Format, cr, w, opts)
+		if err != nil {
+			closeFn()
+			closeAll()
+			return nil, err
+		}
+		outputs = append(outputs, output{target: target, printer: p, close: closeFn})
+	}
+	return outputs, nil
+}
+
+// exitCode determines the exit code from the coverage results. Test failures
+// take precedence over packages that are below their threshold.
+func exitCode(cr *reporter.CoverageReporter, coverages []reporter.Coverage) int {
+	code := ExitOK
+	belowThreshold := func() {
+		if code == ExitOK {
+			code = ExitCoverageTooLow
+		}
+	}
+
+	for _, cov := range coverages {
+		if cov.Status.Failed() {
+			log.Errorf("Tests failed for package %s: %s", cov.PackageName, cov.Status)
+			code = ExitTestsFailed
+			continue
+		}
+		if !cr.Passed(cov) {
+			if cov.Untested() {
+				log.Errorf("Package %s has no test files (untested policy: %s)", cov.PackageName, cr.UntestedPolicy)
+			} else {
+				log.Errorf("Coverage of package %s is %.2f%%, below threshold %.2f%%", cov.PackageName, cov.Percentage, cr.PackageThreshold(cov.PackageName))
+			}
+			belowThreshold()
+		}
+		if cov.Patch != nil && !cr.PatchPassed(*cov.Patch) {
+			log.Errorf("Patch coverage of package %s is %.2f%%, below patch threshold %.2f%%", cov.PackageName, cov.Patch.Percentage(), cr.PatchThreshold)
+			belowThreshold()
+		}
+	}
+
+	if summary := cr.Summarize(coverages); !summary.GlobalPassed {
+		log.Errorf("Total coverage is %.2f%%, below global threshold %.2f%%", summary.Total.Percentage(), cr.GlobalThreshold)
+		belowThreshold()
+	}
+
+	for _, node := range cr.FailedSubtrees(coverages) {
+		log.Errorf("Coverage of %s/... is %.2f%%, below subtree threshold %.2f%%", node.Path, node.Totals.Percentage(), node.Threshold)
+		belowThreshold()
+	}
+
+	for _, regression := range cr.BaselineRegressions(coverages) {
+		log.Errorf("Coverage of package %s dropped to %.2f%%, %.2f points below its baseline %.2f%% (tolerance %.2f)", regression.PackageName, regression.Current, regression.Drop(), regression.Baseline, cr.BaselineTolerance)
+		belowThreshold()
+	}
+
+	if cr.Base != "" {
+		if overall := reporter.OverallPatchCoverage(coverages); !cr.PatchPassed(overall) {
+			log.Errorf("Patch coverage is %.2f%%, below patch threshold %.2f%%", overall.Percentage(), cr.PatchThreshold)
+			belowThreshold()
+		}
+	}
+	return code
 }
 
 // setupLogging sets up logging based on the configuration
@@ -112,9 +392,27 @@ func setupLogging(cfg conf.Config) error {
 	return nil
 }
 
-// removeFilesWithExtension removes files with the given extension in the specified directory
+// removeReportsExcept removes all entries of the reports directory except the named ones
+func removeReportsExcept(dir string, keep ...string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if slices.Contains(keep, entry.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFilesWithExtension removes the per-package reports with the given extension in
+// the specified directory. The combined reports, such as coverage.out, are kept.
 func removeFilesWithExtension(dir, ext string) error {
-	files, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	files, err := filepath.Glob(filepath.Join(dir, "coverage_*"+ext))
 	if err != nil {
 		return fmt.Errorf("error finding files to delete: %w", err)
 	}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	packages := []finder.Package{
+		{Name: "example.com/mod/a", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/b", GoFiles: []string{"b.go"}, TestGoFiles: []string{"b_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/untested", GoFiles: []string{"untested.go"}, Threshold: 50},
+	}
+	covered := reporter.Coverage{PackageName: "example.com/mod/a", Status: reporter.StatusPassed, Percentage: 80, Statements: 10, CoveredStatements: 8}
+	low := reporter.Coverage{PackageName: "example.com/mod/b", Status: reporter.StatusPassed, Percentage: 20, Statements: 10, CoveredStatements: 2}
+	failed := reporter.Coverage{PackageName: "example.com/mod/b", Status: reporter.StatusTestFailed, Percentage: 90, Statements: 10, CoveredStatements: 9}
+	untested := reporter.Coverage{PackageName: "example.com/mod/untested", Status: reporter.StatusNoTestFiles}
+
+	tests := []struct {
+		name            string
+		coverages       []reporter.Coverage
+		untestedPolicy  string
+		globalThreshold float64
+		expected        int
+	}{
+		{"all packages pass", []reporter.Coverage{covered}, reporter.UntestedFail, 0, ExitOK},
+		{"coverage below threshold", []reporter.Coverage{covered, low}, reporter.UntestedFail, 0, ExitCoverageTooLow},
+		{"tests failed", []reporter.Coverage{covered, failed}, reporter.UntestedFail, 0, ExitTestsFailed},
+		{"test failures take precedence over coverage", []reporter.Coverage{low, failed}, reporter.UntestedFail, 0, ExitTestsFailed},
+		{"test failures take precedence regardless of order", []reporter.Coverage{failed, low}, reporter.UntestedFail, 0, ExitTestsFailed},
+		{"untested package fails by policy", []reporter.Coverage{covered, untested}, reporter.UntestedFail, 0, ExitCoverageTooLow},
+		{"untested package ignored by policy", []reporter.Coverage{covered, untested}, reporter.UntestedIgnore, 0, ExitOK},
+		{"total below global threshold", []reporter.Coverage{covered}, reporter.UntestedFail, 90, ExitCoverageTooLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &reporter.CoverageReporter{Packages: packages, UntestedPolicy: tt.untestedPolicy, GlobalThreshold: tt.globalThreshold}
+			for _, cov := range tt.coverages {
+				cr.Overall.Statements += cov.Statements
+				cr.Overall.Covered += cov.CoveredStatements
+			}
+			assert.Equal(t, tt.expected, exitCode(cr, tt.coverages))
+		})
+	}
+}
+
+func TestRunConfigError(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"coverco", "-config", filepath.Join(t.TempDir(), "missing.yaml")}
+
+	assert.Equal(t, ExitConfigOrToolError, run())
+}
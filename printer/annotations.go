@@ -0,0 +1,126 @@
+package printer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// CodeQualityCheckName identifies coverco findings in GitLab Code Quality reports
+const CodeQualityCheckName = "coverco/uncovered"
+
+// GitHubAnnotationLimit is the number of warnings GitHub Actions shows per step
+const GitHubAnnotationLimit = 10
+
+// PrintGitHubAnnotations prints a GitHub Actions warning workflow command for
+// the largest uncovered ranges, so uncovered lines are annotated in pull
+// requests. GitHub drops warnings beyond GitHubAnnotationLimit, so the
+// remaining ranges are summarized in a notice.
+func (cp *CoveragePrinter) PrintGitHubAnnotations(coverages []reporter.Coverage) error {
+	ranges, err := cp.Reporter.UncoveredRanges(coverages)
+	if err != nil {
+		return err
+	}
+
+	var omitted []reporter.UncoveredRange
+	if len(ranges) > GitHubAnnotationLimit {
+		sort.SliceStable(ranges, func(i, j int) bool {
+			return ranges[i].EndLine-ranges[i].StartLine > ranges[j].EndLine-ranges[j].StartLine
+		})
+		ranges, omitted = ranges[:GitHubAnnotationLimit], ranges[GitHubAnnotationLimit:]
+		sort.SliceStable(ranges, func(i, j int) bool {
+			if ranges[i].FileName != ranges[j].FileName {
+				return ranges[i].FileName < ranges[j].FileName
+			}
+			return ranges[i].StartLine < ranges[j].StartLine
+		})
+	}
+
+	for _, r := range ranges {
+		_, err := fmt.Fprintf(cp.Output, "::warning file=%s,line=%d,endLine=%d,title=%s::%s\n",
+			escapeGitHubProperty(r.FileName), r.StartLine, r.EndLine,
+			escapeGitHubProperty("Uncovered code"), escapeGitHubData(uncoveredMessage(r)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(omitted) > 0 {
+		lines := 0
+		for _, r := range omitted {
+			lines += r.EndLine - r.StartLine + 1
+		}
+		_, err := fmt.Fprintf(cp.Output, "::notice title=%s::%s\n", escapeGitHubProperty("Uncovered code"),
+			escapeGitHubData(fmt.Sprintf("%d more uncovered ranges (%d lines) are not annotated", len(omitted), lines)))
+		return err
+	}
+	return nil
+}
+
+// codeQualityIssue is an issue of a GitLab Code Quality report
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+		End   int `json:"end"`
+	} `json:"lines"`
+}
+
+// PrintGitLabCodeQuality prints a GitLab Code Quality report with an issue
+// for every uncovered range
+func (cp *CoveragePrinter) PrintGitLabCodeQuality(coverages []reporter.Coverage) error {
+	ranges, err := cp.Reporter.UncoveredRanges(coverages)
+	if err != nil {
+		return err
+	}
+
+	issues := make([]codeQualityIssue, 0, len(ranges))
+	for _, r := range ranges {
+		issue := codeQualityIssue{
+			Description: uncoveredMessage(r),
+			CheckName:   CodeQualityCheckName,
+			Severity:    "minor",
+			Location:    codeQualityLocation{Path: r.FileName},
+		}
+		issue.Location.Lines.Begin = r.StartLine
+		issue.Location.Lines.End = r.EndLine
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s:%s:%d-%d", CodeQualityCheckName, r.FileName, r.StartLine, r.EndLine)))
+		issue.Fingerprint = hex.EncodeToString(sum[:])
+		issues = append(issues, issue)
+	}
+
+	encoder := json.NewEncoder(cp.Output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// uncoveredMessage describes an uncovered range
+func uncoveredMessage(r reporter.UncoveredRange) string {
+	if r.StartLine == r.EndLine {
+		return fmt.Sprintf("Line %d is not covered by tests", r.StartLine)
+	}
+	return fmt.Sprintf("Lines %d-%d are not covered by tests", r.StartLine, r.EndLine)
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
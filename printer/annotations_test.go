@@ -0,0 +1,49 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeGitHubData(t *testing.T) {
+	assert.Equal(t, "100%25 of a, b: c%0Anext%0D", escapeGitHubData("100% of a, b: c\nnext\r"))
+}
+
+func TestEscapeGitHubProperty(t *testing.T) {
+	assert.Equal(t, "dir%2Ca%3Ab.go 100%25%0A", escapeGitHubProperty("dir,a:b.go 100%\n"))
+}
+
+func TestPrintGitHubAnnotationsLimit(t *testing.T) {
+	profile := "mode: set\n"
+	for i := 0; i < GitHubAnnotationLimit+2; i++ {
+		// Ranges of one line, except the last one spanning two lines
+		end := 10*i + 1
+		if i == GitHubAnnotationLimit+1 {
+			end++
+		}
+		profile += fmt.Sprintf("example.com/mod/pkg/a.go:%d.2,%d.10 1 0\n", 10*i+1, end)
+	}
+	profileFile := filepath.Join(t.TempDir(), "coverage.out")
+	require.NoError(t, os.WriteFile(profileFile, []byte(profile), 0644))
+
+	var buf bytes.Buffer
+	cp := &CoveragePrinter{
+		Reporter: &reporter.CoverageReporter{Paths: &reporter.PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src/mod", RootDir: "/src/mod"}},
+		Output:   &buf,
+	}
+	require.NoError(t, cp.PrintGitHubAnnotations([]reporter.Coverage{{PackageName: "example.com/mod/pkg", ProfileFile: profileFile}}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, GitHubAnnotationLimit+1)
+	assert.Equal(t, "::warning file=pkg/a.go,line=1,endLine=1,title=Uncovered code::Line 1 is not covered by tests", lines[0])
+	assert.Equal(t, "::warning file=pkg/a.go,line=111,endLine=112,title=Uncovered code::Lines 111-112 are not covered by tests", lines[GitHubAnnotationLimit-1])
+	assert.Equal(t, "::notice title=Uncovered code::2 more uncovered ranges (2 lines) are not annotated", lines[GitHubAnnotationLimit])
+}
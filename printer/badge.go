@@ -0,0 +1,109 @@
+package printer
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// Badge colours for passing and failing coverage
+const (
+	BadgeColorPassed = "#4c1"
+	BadgeColorFailed = "#e05d44"
+)
+
+// BadgeLabel is the left-hand text of coverage badges
+const BadgeLabel = "coverage"
+
+// PrintCoverageBadge writes an SVG badge of the aggregate coverage. It is
+// red when any package fails its threshold or the total fails the global
+// threshold. With a badge directory, a badge per package is written there too.
+func (cp *CoveragePrinter) PrintCoverageBadge(coverages []reporter.Coverage) error {
+	summary := cp.Reporter.Summarize(coverages)
+	color := BadgeColorPassed
+	if summary.Failed > 0 || !summary.GlobalPassed {
+		color = BadgeColorFailed
+	}
+	if err := WriteBadge(cp.Output, BadgeLabel, fmt.Sprintf("%.1f%%", summary.Total.Percentage()), color); err != nil {
+		return err
+	}
+
+	if cp.Options.BadgeDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(cp.Options.BadgeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create badge directory: %w", err)
+	}
+	for _, cov := range coverages {
+		color := BadgeColorPassed
+		if !cp.Reporter.Passed(cov) {
+			color = BadgeColorFailed
+		}
+		fileName := filepath.Join(cp.Options.BadgeDir, badgeFileName(cov.PackageName))
+		f, err := os.Create(fileName)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", fileName, err)
+		}
+		err = WriteBadge(f, BadgeLabel, fmt.Sprintf("%.1f%%", cov.Percentage), color)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+	}
+	return nil
+}
+
+// badgeFileName returns the flat badge file name of a package
+func badgeFileName(packageName string) string {
+	return flatName(packageName) + ".svg"
+}
+
+// WriteBadge writes a flat SVG badge with the label on the left and the
+// value on a background of the given colour on the right
+func WriteBadge(w io.Writer, label, value, color string) error {
+	labelWidth := badgeTextWidth(label)
+	valueWidth := badgeTextWidth(value)
+	return badgeTemplate.Execute(w, struct {
+		Label, Value, Color           string
+		Width, LabelWidth, ValueWidth int
+		LabelX, ValueX                float64
+	}{
+		Label:      label,
+		Value:      value,
+		Color:      color,
+		Width:      labelWidth + valueWidth,
+		LabelWidth: labelWidth,
+		ValueWidth: valueWidth,
+		LabelX:     float64(labelWidth) / 2,
+		ValueX:     float64(labelWidth) + float64(valueWidth)/2,
+	})
+}
+
+// badgeTextWidth estimates the rendered width of badge text in 11px Verdana, plus padding
+func badgeTextWidth(text string) int {
+	return 7*len(text) + 10
+}
+
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Value}}">
+<title>{{.Label}}: {{.Value}}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+<rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/>
+<rect width="{{.Width}}" height="20" fill="url(#s)"/>
+</g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{.Label}}</text>
+<text x="{{.LabelX}}" y="14">{{.Label}}</text>
+<text x="{{.ValueX}}" y="15" fill="#010101" fill-opacity=".3">{{.Value}}</text>
+<text x="{{.ValueX}}" y="14">{{.Value}}</text>
+</g>
+</svg>
+`))
@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintCoverageBadgeColor(t *testing.T) {
+	packages := []finder.Package{
+		{Name: "example.com/mod/a", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/b", GoFiles: []string{"b.go"}, TestGoFiles: []string{"b_test.go"}, Threshold: 50},
+	}
+	overall := reporter.Totals{Statements: 10, Covered: 6}
+	tests := []struct {
+		name            string
+		bPercentage     float64
+		globalThreshold float64
+		color           string
+	}{
+		{"all packages pass", 50, 0, BadgeColorPassed},
+		{"global threshold met", 50, 60, BadgeColorPassed},
+		{"package below threshold", 49.9, 0, BadgeColorFailed},
+		{"global threshold missed", 50, 60.1, BadgeColorFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cp := NewCoveragePrinter(&reporter.CoverageReporter{Packages: packages, Overall: overall, GlobalThreshold: tt.globalThreshold}, &buf)
+			require.NoError(t, cp.PrintCoverageBadge([]reporter.Coverage{
+				{PackageName: "example.com/mod/a", Status: reporter.StatusPassed, Percentage: 70},
+				{PackageName: "example.com/mod/b", Status: reporter.StatusPassed, Percentage: tt.bPercentage},
+			}))
+			assert.Contains(t, buf.String(), `fill="`+tt.color+`"`)
+			assert.Contains(t, buf.String(), `aria-label="coverage: 60.0%"`)
+		})
+	}
+}
+
+func TestPrintCoverageBadgeDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "badges")
+	cr := &reporter.CoverageReporter{Packages: []finder.Package{
+		{Name: "example.com/mod/a", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/b", GoFiles: []string{"b.go"}, TestGoFiles: []string{"b_test.go"}, Threshold: 50},
+	}}
+	cp := NewCoveragePrinter(cr, &bytes.Buffer{})
+	cp.Options.BadgeDir = dir
+	require.NoError(t, cp.PrintCoverageBadge([]reporter.Coverage{
+		{PackageName: "example.com/mod/a", Status: reporter.StatusPassed, Percentage: 70},
+		{PackageName: "example.com/mod/b", Status: reporter.StatusTestFailed, Percentage: 90},
+	}))
+
+	a, err := os.ReadFile(filepath.Join(dir, "example.com_mod_a.svg"))
+	require.NoError(t, err)
+	assert.Contains(t, string(a), `fill="`+BadgeColorPassed+`"`)
+	assert.Contains(t, string(a), ">70.0%</text>")
+	b, err := os.ReadFile(filepath.Join(dir, "example.com_mod_b.svg"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `fill="`+BadgeColorFailed+`"`)
+}
+
+func TestWriteBadgeEscapesText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteBadge(&buf, `<cov & "age">`, "1<2", BadgeColorPassed))
+	svg := buf.String()
+	assert.NotContains(t, svg, "<cov")
+	assert.NotContains(t, svg, "1<2")
+	assert.Contains(t, svg, "<title>&lt;cov &amp; &#34;age&#34;&gt;: 1&lt;2</title>")
+	assert.Contains(t, svg, `aria-label="&lt;cov &amp; &#34;age&#34;&gt;: 1&lt;2"`)
+}
@@ -10,24 +10,19 @@ func (cp *CoveragePrinter) PrintCoverageCSV(coverages []reporter.Coverage) error
 	writer := csv.NewWriter(cp.Output)
 
 	// Write CSV header
-	if err := writer.Write([]string{"Package Name", "Coverage Percentage", "Threshold"}); err != nil {
+	if err := writer.Write([]string{"Package Name", "Coverage Percentage", "Threshold", "Status"}); err != nil {
 		return err
 	}
 
 	// Write CSV rows
 	for _, cov := range coverages {
-		packageThreshold := cp.Reporter.DefaultCoverageThreshold
-		for _, pkg := range cp.Reporter.Packages {
-			if pkg.Name == cov.PackageName {
-				packageThreshold = pkg.Threshold
-				break
-			}
-		}
+		packageThreshold := cp.Reporter.PackageThreshold(cov.PackageName)
 
 		row := []string{
 			cov.PackageName,
 			fmt.Sprintf("%.2f%%", cov.Percentage),
 			fmt.Sprintf("%.2f%%", packageThreshold),
+			string(cov.Status),
 		}
 
 		if err := writer.Write(row); err != nil {
@@ -35,6 +30,18 @@ func (cp *CoveragePrinter) PrintCoverageCSV(coverages []reporter.Coverage) error
 		}
 	}
 
+	// Write summary row
+	summary := cp.Reporter.Summarize(coverages)
+	total := []string{
+		"Total",
+		fmt.Sprintf("%.2f%%", summary.Total.Percentage()),
+		cp.globalThresholdText(),
+		fmt.Sprintf("%d passed, %d failed", summary.Passed, summary.Failed),
+	}
+	if err := writer.Write(total); err != nil {
+		return err
+	}
+
 	writer.Flush()
 
 	if err := writer.Error(); err != nil {
@@ -0,0 +1,92 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/olekukonko/tablewriter"
+)
+
+// HistoryTrendRuns is the number of most recent runs shown in trend lines
+const HistoryTrendRuns = 20
+
+// sparkBars are the levels of a trend line, from lowest to highest coverage
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// PrintHistoryTrends prints the trend of the total and per-package coverage
+// over the most recent runs of the history
+func PrintHistoryTrends(w io.Writer, entries []reporter.HistoryEntry, runs int) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No coverage history recorded yet.")
+		return
+	}
+	if runs > 0 && len(entries) > runs {
+		entries = entries[len(entries)-runs:]
+	}
+
+	first, last := entries[0], entries[len(entries)-1]
+	fmt.Fprintf(w, "Coverage history: %d runs from %s to %s\n", len(entries), first.Timestamp.Format("2006-01-02 15:04"), last.Timestamp.Format("2006-01-02 15:04"))
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Package Name", "Trend", "First", "Latest", "Change"})
+
+	totals := make([]float64, len(entries))
+	for i, entry := range entries {
+		totals[i] = entry.Total
+	}
+	table.Append(trendRow("Total", totals))
+
+	var names []string
+	for name := range last.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var series []float64
+		for _, entry := range entries {
+			if percentage, ok := entry.Packages[name]; ok {
+				series = append(series, percentage)
+			}
+		}
+		table.Append(trendRow(name, series))
+	}
+
+	table.Render()
+}
+
+// trendRow formats the coverage series of a package as a table row
+func trendRow(name string, series []float64) []string {
+	first, last := series[0], series[len(series)-1]
+	return []string{
+		name,
+		sparkline(series),
+		fmt.Sprintf("%.2f%%", first),
+		fmt.Sprintf("%.2f%%", last),
+		deltaText(last-first, true),
+	}
+}
+
+// sparkline renders the series as bars scaled between its lowest and highest value;
+// a constant series is drawn at the lowest level
+func sparkline(series []float64) string {
+	if len(series) == 0 {
+		return ""
+	}
+	lo, hi := series[0], series[0]
+	for _, v := range series {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range series {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkBars)-1))
+		}
+		sb.WriteRune(sparkBars[level])
+	}
+	return sb.String()
+}
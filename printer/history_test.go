@@ -0,0 +1,52 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		series   []float64
+		expected string
+	}{
+		{nil, ""},
+		{[]float64{42}, "▁"},
+		{[]float64{50, 50, 50}, "▁▁▁"},
+		{[]float64{0, 100}, "▁█"},
+		{[]float64{10, 20, 30, 40, 50, 60, 70, 80}, "▁▂▃▄▅▆▇█"},
+		{[]float64{80, 60, 70}, "█▁▄"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, sparkline(tt.series), "%v", tt.series)
+	}
+}
+
+func TestPrintHistoryTrends(t *testing.T) {
+	var buf bytes.Buffer
+	PrintHistoryTrends(&buf, nil, HistoryTrendRuns)
+	assert.Equal(t, "No coverage history recorded yet.\n", buf.String())
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []reporter.HistoryEntry{
+		{Timestamp: start, Total: 10, Packages: map[string]float64{"example.com/mod/old": 10}},
+		{Timestamp: start.Add(time.Hour), Total: 50, Packages: map[string]float64{"example.com/mod/a": 40, "example.com/mod/b": 60}},
+		{Timestamp: start.Add(2 * time.Hour), Total: 60, Packages: map[string]float64{"example.com/mod/a": 40, "example.com/mod/b": 80}},
+		{Timestamp: start.Add(3 * time.Hour), Total: 55, Packages: map[string]float64{"example.com/mod/a": 40}},
+	}
+
+	buf.Reset()
+	PrintHistoryTrends(&buf, entries, 3)
+	output := buf.String()
+	assert.Contains(t, output, "Coverage history: 3 runs from 2024-05-01 13:00 to 2024-05-01 15:00")
+	assert.Regexp(t, `Total\s+\|\s+▁█▄\s+\|\s+50\.00%\s+\|\s+55\.00%\s+\|\s+\+5\.00`, output)
+	assert.Regexp(t, `example\.com/mod/a\s+\|\s+▁▁▁\s+\|\s+40\.00%\s+\|\s+40\.00%\s+\|\s+\+0\.00`, output)
+	// only packages of the latest run are listed
+	assert.NotContains(t, output, "example.com/mod/b")
+	assert.NotContains(t, output, "example.com/mod/old")
+}
@@ -0,0 +1,291 @@
+package printer
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// HTMLDirName is the directory of the HTML report inside the reports directory
+const HTMLDirName = "html"
+
+// htmlPackage is a row of the HTML index
+type htmlPackage struct {
+	Name       string
+	Page       string
+	Dir        string
+	Percentage float64
+	Threshold  float64
+	Status     string
+	Passed     bool
+	Statements int
+	Covered    int
+	Files      []htmlFile
+}
+
+// htmlFile is a source file of a package page
+type htmlFile struct {
+	Name       string
+	Page       string
+	Package    htmlLink
+	Percentage float64
+	Statements int
+	Covered    int
+	Lines      []htmlLine
+}
+
+// htmlLink is a link to another page of the report
+type htmlLink struct {
+	Name string
+	Page string
+}
+
+// htmlLine is a line of a source view. Class is "covered", "uncovered" or
+// empty for lines without statements.
+type htmlLine struct {
+	Number int
+	Text   string
+	Class  string
+}
+
+// PrintCoverageHTML writes a self-contained static HTML report to the html
+// directory of the reports directory: an index of all packages, a page per
+// package and a source view per file. The location of the index is printed
+// to the output.
+func (cp *CoveragePrinter) PrintCoverageHTML(coverages []reporter.Coverage) error {
+	dir := filepath.Join(cp.Reporter.ReportsDir, HTMLDirName)
+	if err := os.MkdirAll(filepath.Join(dir, "files"), 0755); err != nil {
+		return fmt.Errorf("failed to create HTML report directory: %w", err)
+	}
+
+	merged, err := reporter.MergedProfiles(coverages)
+	if err != nil {
+		return err
+	}
+	profilesByPkg := make(map[string][]*reporter.Profile)
+	for _, profile := range merged {
+		pkg := path.Dir(profile.FileName)
+		profilesByPkg[pkg] = append(profilesByPkg[pkg], profile)
+	}
+
+	packages := make([]htmlPackage, 0, len(coverages))
+	for _, cov := range coverages {
+		pkg := htmlPackage{
+			Name:       cov.PackageName,
+			Page:       htmlPageName(cov.PackageName),
+			Percentage: cov.Percentage,
+			Threshold:  cp.Reporter.PackageThreshold(cov.PackageName),
+			Status:     cp.statusText(cov),
+			Passed:     cp.Reporter.Passed(cov),
+			Statements: cov.Statements,
+			Covered:    cov.CoveredStatements,
+		}
+		if meta, ok := cp.Reporter.Package(cov.PackageName); ok {
+			pkg.Dir = meta.Dir
+		}
+
+		for _, profile := range profilesByPkg[cov.PackageName] {
+			file, err := cp.htmlFile(profile)
+			if err != nil {
+				return err
+			}
+			file.Package = htmlLink{Name: pkg.Name, Page: pkg.Page}
+			if err := writeHTMLPage(filepath.Join(dir, "files", file.Page), "file", file); err != nil {
+				return err
+			}
+			file.Lines = nil
+			pkg.Files = append(pkg.Files, file)
+		}
+
+		if err := writeHTMLPage(filepath.Join(dir, pkg.Page), "package", pkg); err != nil {
+			return err
+		}
+		packages = append(packages, pkg)
+	}
+
+	index := filepath.Join(dir, "index.html")
+	if err := writeHTMLPage(index, "index", struct {
+		Metadata        reporter.RunMetadata
+		Summary         reporter.Summary
+		GlobalThreshold float64
+		Packages        []htmlPackage
+	}{
+		Metadata:        cp.Reporter.Metadata(),
+		Summary:         cp.Reporter.Summarize(coverages),
+		GlobalThreshold: cp.Reporter.GlobalThreshold,
+		Packages:        packages,
+	}); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cp.Output, "HTML coverage report written to %s\n", index)
+	return err
+}
+
+// htmlFile reads the source of the profile and marks its covered and
+// uncovered lines. Sources that cannot be read are listed without a source view.
+func (cp *CoveragePrinter) htmlFile(profile *reporter.Profile) (htmlFile, error) {
+	file := htmlFile{
+		Name: cp.Reporter.Paths.RelPath(profile.FileName),
+		Page: htmlPageName(profile.FileName),
+	}
+	for _, b := range profile.Blocks {
+		file.Statements += b.NumStmt
+		if b.Count > 0 {
+			file.Covered += b.NumStmt
+		}
+	}
+	if file.Statements > 0 {
+		file.Percentage = 100 * float64(file.Covered) / float64(file.Statements)
+	}
+
+	src, err := os.Open(cp.Reporter.Paths.AbsPath(profile.FileName))
+	if err != nil {
+		return file, nil
+	}
+	defer src.Close()
+
+	counts := reporter.LineCounts(profile)
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := htmlLine{Number: number, Text: scanner.Text()}
+		if count, ok := counts[number]; ok {
+			line.Class = "covered"
+			if count == 0 {
+				line.Class = "uncovered"
+			}
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return file, fmt.Errorf("failed to read %s: %w", profile.FileName, err)
+	}
+	return file, nil
+}
+
+// htmlPageName returns a flat page file name for an import path or file name
+func htmlPageName(name string) string {
+	return flatName(name) + ".html"
+}
+
+// flatName replaces the path separators of an import path or file name so
+// it can be used as a file name in a single directory
+func flatName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
+// writeHTMLPage renders the named template to the file
+func writeHTMLPage(fileName, name string, data any) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", fileName, err)
+	}
+	defer f.Close()
+
+	if err := htmlTemplates.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+var htmlTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"pct": func(p float64) string { return fmt.Sprintf("%.2f%%", p) },
+	"width": func(p float64) string {
+		return fmt.Sprintf("%.1f%%", min(max(p, 0), 100))
+	},
+}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.}} - coverco</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 12px; text-align: left; border-bottom: 1px solid #d0d7de; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.bar { width: 160px; height: 10px; background: #ffebe9; border-radius: 3px; overflow: hidden; }
+.bar span { display: block; height: 100%; background: #2da44e; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+pre { margin: 0; font-size: 13px; line-height: 1.4; }
+.src td { border: none; padding: 0 8px; }
+.src td.ln { color: #8c959f; text-align: right; user-select: none; }
+.src tr.covered td.code { background: #dafbe1; }
+.src tr.uncovered td.code { background: #ffebe9; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "bar"}}<div class="bar"><span style="width: {{width .}}"></span></div>{{end}}
+
+{{define "index"}}{{template "head" "Coverage report"}}
+<h1>Coverage report</h1>
+<p>
+{{with .Metadata.Module}}Module <code>{{.}}</code> · {{end}}
+{{with .Metadata.GitCommit}}Commit <code>{{.}}</code> · {{end}}
+{{.Metadata.Timestamp.UTC.Format "2006-01-02 15:04:05 UTC"}}
+</p>
+<p class="{{if .Summary.GlobalPassed}}passed{{else}}failed{{end}}">
+Total coverage <strong>{{pct .Summary.Total.Percentage}}</strong>
+({{.Summary.Total.Covered}}/{{.Summary.Total.Statements}} statements){{if .GlobalThreshold}}, global threshold {{pct .GlobalThreshold}}{{end}}.
+{{.Summary.Passed}} packages passed, {{.Summary.Failed}} failed.
+</p>
+<table>
+<tr><th>Package</th><th></th><th>Coverage</th><th>Threshold</th><th>Statements</th><th>Status</th><th>Directory</th></tr>
+{{range .Packages}}<tr class="{{if .Passed}}passed{{else}}failed{{end}}">
+<td><a href="{{.Page}}">{{.Name}}</a></td>
+<td>{{template "bar" .Percentage}}</td>
+<td class="num">{{pct .Percentage}}</td>
+<td class="num">{{pct .Threshold}}</td>
+<td class="num">{{.Covered}}/{{.Statements}}</td>
+<td>{{.Status}}</td>
+<td><code>{{.Dir}}</code></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+{{end}}
+
+{{define "package"}}{{template "head" .Name}}
+<p><a href="index.html">&larr; All packages</a></p>
+<h1>{{.Name}}</h1>
+<p class="{{if .Passed}}passed{{else}}failed{{end}}">
+Coverage <strong>{{pct .Percentage}}</strong> ({{.Covered}}/{{.Statements}} statements), threshold {{pct .Threshold}}, status {{.Status}}.
+</p>
+{{if .Files}}<table>
+<tr><th>File</th><th></th><th>Coverage</th><th>Statements</th></tr>
+{{range .Files}}<tr>
+<td><a href="files/{{.Page}}">{{.Name}}</a></td>
+<td>{{template "bar" .Percentage}}</td>
+<td class="num">{{pct .Percentage}}</td>
+<td class="num">{{.Covered}}/{{.Statements}}</td>
+</tr>
+{{end}}</table>
+{{else}}<p>No coverage profile was recorded for this package.</p>
+{{end}}</body>
+</html>
+{{end}}
+
+{{define "file"}}{{template "head" .Name}}
+<p><a href="../index.html">&larr; All packages</a> · <a href="../{{.Package.Page}}">{{.Package.Name}}</a></p>
+<h1>{{.Name}}</h1>
+<p>Coverage <strong>{{pct .Percentage}}</strong> ({{.Covered}}/{{.Statements}} statements)</p>
+{{if .Lines}}<table class="src">
+{{range .Lines}}<tr{{with .Class}} class="{{.}}"{{end}}><td class="ln">{{.Number}}</td><td class="code"><pre>{{.Text}}</pre></td></tr>
+{{end}}</table>
+{{else}}<p>The source of this file could not be read.</p>
+{{end}}</body>
+</html>
+{{end}}
+`))
@@ -0,0 +1,132 @@
+package printer
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// JSONSchemaVersion is the version of the JSON report schema. It is
+// incremented whenever a field is removed or changes meaning.
+const JSONSchemaVersion = 1
+
+// JSONReport is the top-level document written by PrintCoverageJSON
+type JSONReport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Metadata      JSONMetadata  `json:"metadata"`
+	Packages      []JSONPackage `json:"packages"`
+	Untested      []string      `json:"untested"`
+	Summary       JSONSummary   `json:"summary"`
+	Patch         *JSONPatch    `json:"patch,omitempty"`
+}
+
+// JSONMetadata describes the coverage run
+type JSONMetadata struct {
+	Module    string    `json:"module"`
+	GoVersion string    `json:"go_version"`
+	Timestamp time.Time `json:"timestamp"`
+	GitCommit string    `json:"git_commit"`
+}
+
+// JSONPackage holds the coverage result of a single package
+type JSONPackage struct {
+	Name       string     `json:"name"`
+	Percentage float64    `json:"percentage"`
+	Threshold  float64    `json:"threshold"`
+	Passed     bool       `json:"passed"`
+	Status     string     `json:"status"`
+	ReportFile string     `json:"report_file"`
+	Dir        string     `json:"dir"`
+	IsMain     bool       `json:"is_main"`
+	HasTests   bool       `json:"has_tests"`
+	Patch      *JSONPatch `json:"patch,omitempty"`
+}
+
+// JSONSummary holds the aggregate coverage of all packages
+type JSONSummary struct {
+	Statements      int     `json:"statements"`
+	Covered         int     `json:"covered"`
+	Percentage      float64 `json:"percentage"`
+	GlobalThreshold float64 `json:"global_threshold"`
+	GlobalPassed    bool    `json:"global_passed"`
+	Passed          int     `json:"passed"`
+	Failed          int     `json:"failed"`
+}
+
+// JSONPatch holds the coverage of the lines changed since the base ref
+type JSONPatch struct {
+	Base       string  `json:"base"`
+	Lines      int     `json:"lines"`
+	Covered    int     `json:"covered"`
+	Percentage float64 `json:"percentage"`
+	Threshold  float64 `json:"threshold"`
+	Passed     bool    `json:"passed"`
+}
+
+// PrintCoverageJSON prints the coverage data as a JSON document
+func (cp *CoveragePrinter) PrintCoverageJSON(coverages []reporter.Coverage) error {
+	metadata := cp.Reporter.Metadata()
+	report := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Metadata: JSONMetadata{
+			Module:    metadata.Module,
+			GoVersion: metadata.GoVersion,
+			Timestamp: metadata.Timestamp.UTC(),
+			GitCommit: metadata.GitCommit,
+		},
+		Packages: make([]JSONPackage, 0, len(coverages)),
+		Untested: []string{},
+	}
+
+	for _, cov := range coverages {
+		pkg := JSONPackage{
+			Name:       cov.PackageName,
+			Percentage: cov.Percentage,
+			Threshold:  cp.Reporter.PackageThreshold(cov.PackageName),
+			Passed:     cp.Reporter.Passed(cov),
+			Status:     string(cov.Status),
+			ReportFile: cp.Reporter.ReportFile(cov),
+		}
+		if meta, ok := cp.Reporter.Package(cov.PackageName); ok {
+			pkg.Dir = meta.Dir
+			pkg.IsMain = meta.IsMain
+			pkg.HasTests = meta.HasTests()
+		}
+		if cov.Patch != nil {
+			pkg.Patch = cp.jsonPatch(*cov.Patch)
+		}
+		report.Packages = append(report.Packages, pkg)
+		if cov.Untested() {
+			report.Untested = append(report.Untested, cov.PackageName)
+		}
+	}
+	summary := cp.Reporter.Summarize(coverages)
+	report.Summary = JSONSummary{
+		Statements:      summary.Total.Statements,
+		Covered:         summary.Total.Covered,
+		Percentage:      summary.Total.Percentage(),
+		GlobalThreshold: cp.Reporter.GlobalThreshold,
+		GlobalPassed:    summary.GlobalPassed,
+		Passed:          summary.Passed,
+		Failed:          summary.Failed,
+	}
+	if cp.Reporter.Base != "" {
+		report.Patch = cp.jsonPatch(reporter.OverallPatchCoverage(coverages))
+	}
+
+	encoder := json.NewEncoder(cp.Output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func (cp *CoveragePrinter) jsonPatch(patch reporter.PatchCoverage) *JSONPatch {
+	return &JSONPatch{
+		Base:       cp.Reporter.Base,
+		Lines:      patch.Lines,
+		Covered:    patch.Covered,
+		Percentage: patch.Percentage(),
+		Threshold:  cp.Reporter.PatchThreshold,
+		Passed:     cp.Reporter.PatchPassed(patch),
+	}
+}
@@ -0,0 +1,90 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintCoverageJSON(t *testing.T) {
+	var buf bytes.Buffer
+	cp := &CoveragePrinter{
+		Reporter: &reporter.CoverageReporter{
+			Packages: []finder.Package{
+				{Name: "example.com/mod/pkg", Dir: "/src/mod/pkg", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+				{Name: "example.com/mod/cmd", Dir: "/src/mod/cmd", GoFiles: []string{"main.go"}, IsMain: true, Threshold: 50},
+			},
+			Paths:           &reporter.PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src/mod", RootDir: "/src/mod"},
+			StartedAt:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			OutputFormat:    reporter.FormatLcov,
+			KeepReports:     true,
+			UntestedPolicy:  reporter.UntestedFail,
+			GlobalThreshold: 40,
+			Overall:         reporter.Totals{Statements: 20, Covered: 12},
+		},
+		Output: &buf,
+	}
+	require.NoError(t, cp.PrintCoverageJSON([]reporter.Coverage{
+		{PackageName: "example.com/mod/pkg", Percentage: 75, Status: reporter.StatusPassed, CoverageFile: "reports/coverage_pkg.lcov"},
+		{PackageName: "example.com/mod/cmd", Status: reporter.StatusNoTestFiles},
+	}))
+
+	// The Go version and git commit depend on the environment
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	metadata := report["metadata"].(map[string]any)
+	metadata["go_version"], metadata["git_commit"] = "", ""
+	normalized, err := json.Marshal(report)
+	require.NoError(t, err)
+
+	expected := `{
+  "schema_version": 1,
+  "metadata": {
+    "module": "example.com/mod",
+    "go_version": "",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "git_commit": ""
+  },
+  "packages": [
+    {
+      "name": "example.com/mod/pkg",
+      "percentage": 75,
+      "threshold": 50,
+      "passed": true,
+      "status": "passed",
+      "report_file": "reports/coverage_pkg.lcov",
+      "dir": "/src/mod/pkg",
+      "is_main": false,
+      "has_tests": true
+    },
+    {
+      "name": "example.com/mod/cmd",
+      "percentage": 0,
+      "threshold": 50,
+      "passed": false,
+      "status": "no-test-files",
+      "report_file": "",
+      "dir": "/src/mod/cmd",
+      "is_main": true,
+      "has_tests": false
+    }
+  ],
+  "untested": ["example.com/mod/cmd"],
+  "summary": {
+    "statements": 20,
+    "covered": 12,
+    "percentage": 60,
+    "global_threshold": 40,
+    "global_passed": true,
+    "passed": 1,
+    "failed": 1
+  }
+}`
+	assert.JSONEq(t, expected, string(normalized))
+}
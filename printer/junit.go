@@ -0,0 +1,159 @@
+package printer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// JUnitCoverageSuite is the name of the test suite holding the coverage checks
+const JUnitCoverageSuite = "coverco.coverage"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// PrintCoverageJUnit prints the coverage checks as a JUnit XML report: every
+// package is a testcase that fails when it does not meet its threshold. When
+// test results are recorded, every tested package also gets a suite with
+// the results of its go tests.
+func (cp *CoveragePrinter) PrintCoverageJUnit(coverages []reporter.Coverage) error {
+	report := junitTestSuites{Name: "coverco"}
+
+	suite := junitTestSuite{Name: JUnitCoverageSuite, Time: "0", Timestamp: cp.Reporter.Metadata().Timestamp.UTC().Format("2006-01-02T15:04:05")}
+	for _, cov := range coverages {
+		suite.add(cp.coverageTestCase(cov))
+	}
+	report.add(suite)
+
+	if cp.Reporter.RecordTests {
+		for _, cov := range coverages {
+			if suite := goTestSuite(cov); suite.Tests > 0 {
+				report.add(suite)
+			}
+		}
+	}
+
+	if _, err := fmt.Fprint(cp.Output, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(cp.Output)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(cp.Output)
+	return err
+}
+
+// coverageTestCase checks the coverage of a package against its threshold
+func (cp *CoveragePrinter) coverageTestCase(cov reporter.Coverage) junitTestCase {
+	threshold := cp.Reporter.PackageThreshold(cov.PackageName)
+	tc := junitTestCase{ClassName: JUnitCoverageSuite, Name: cov.PackageName, Time: "0"}
+	switch {
+	case cov.Untested() && cp.Reporter.UntestedPolicy == reporter.UntestedIgnore:
+		tc.Skipped = &junitMessage{Message: "package has no test files"}
+	case cov.Status.Failed():
+		tc.Failure = &junitMessage{
+			Message: fmt.Sprintf("tests of package %s: %s", cov.PackageName, cov.Status),
+			Text:    cov.Output,
+		}
+	case cov.Untested() && !cp.Reporter.Passed(cov):
+		tc.Failure = &junitMessage{
+			Message: fmt.Sprintf("package has no test files (untested policy: %s)", cp.Reporter.UntestedPolicy),
+		}
+	case !cp.Reporter.Passed(cov):
+		tc.Failure = &junitMessage{
+			Message: fmt.Sprintf("coverage %.2f%% is below threshold %.2f%%", cov.Percentage, threshold),
+		}
+	default:
+		tc.SystemOut = fmt.Sprintf("coverage %.2f%% meets threshold %.2f%%", cov.Percentage, threshold)
+	}
+	return tc
+}
+
+// goTestSuite holds the go test results of a package. A package whose tests
+// failed without reporting individual results, such as a build failure,
+// gets a single failing testcase with the go test output.
+func goTestSuite(cov reporter.Coverage) junitTestSuite {
+	suite := junitTestSuite{Name: cov.PackageName}
+	var elapsed float64
+	for _, test := range cov.Tests {
+		tc := junitTestCase{ClassName: cov.PackageName, Name: test.Name, Time: fmt.Sprintf("%.3f", test.Elapsed)}
+		switch test.Outcome {
+		case reporter.TestFail:
+			tc.Failure = &junitMessage{Message: "test failed", Text: test.Output}
+		case reporter.TestSkip:
+			tc.Skipped = &junitMessage{Message: strings.TrimSpace(test.Output)}
+		default:
+			tc.SystemOut = test.Output
+		}
+		// Subtest durations are included in their parent test
+		if !strings.Contains(test.Name, "/") {
+			elapsed += test.Elapsed
+		}
+		suite.add(tc)
+	}
+
+	if cov.Status.Failed() && suite.Failures == 0 {
+		suite.add(junitTestCase{
+			ClassName: cov.PackageName,
+			Name:      string(cov.Status),
+			Time:      "0",
+			Failure:   &junitMessage{Message: fmt.Sprintf("go test: %s", cov.Status), Text: cov.Output},
+		})
+	}
+	suite.Time = fmt.Sprintf("%.3f", elapsed)
+	return suite
+}
+
+// add appends the testcase and updates the suite counters
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	if tc.Skipped != nil {
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+// add appends the suite and updates the report counters
+func (r *junitTestSuites) add(suite junitTestSuite) {
+	r.Tests += suite.Tests
+	r.Failures += suite.Failures
+	r.Skipped += suite.Skipped
+	r.Suites = append(r.Suites, suite)
+}
@@ -0,0 +1,73 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageTestCase(t *testing.T) {
+	cp := &CoveragePrinter{Reporter: &reporter.CoverageReporter{
+		Packages: []finder.Package{
+			{Name: "example.com/mod/ok", GoFiles: []string{"ok.go"}, TestGoFiles: []string{"ok_test.go"}, Threshold: 50},
+			{Name: "example.com/mod/low", GoFiles: []string{"low.go"}, TestGoFiles: []string{"low_test.go"}, Threshold: 50},
+			{Name: "example.com/mod/broken", GoFiles: []string{"broken.go"}, TestGoFiles: []string{"broken_test.go"}, Threshold: 50},
+			{Name: "example.com/mod/untested", GoFiles: []string{"untested.go"}, Threshold: 50},
+		},
+		UntestedPolicy: reporter.UntestedIgnore,
+	}}
+
+	tc := cp.coverageTestCase(reporter.Coverage{PackageName: "example.com/mod/ok", Status: reporter.StatusPassed, Percentage: 75})
+	assert.Nil(t, tc.Failure)
+	assert.Equal(t, "coverage 75.00% meets threshold 50.00%", tc.SystemOut)
+
+	tc = cp.coverageTestCase(reporter.Coverage{PackageName: "example.com/mod/low", Status: reporter.StatusPassed, Percentage: 25})
+	require.NotNil(t, tc.Failure)
+	assert.Equal(t, "coverage 25.00% is below threshold 50.00%", tc.Failure.Message)
+
+	tc = cp.coverageTestCase(reporter.Coverage{PackageName: "example.com/mod/broken", Status: reporter.StatusBuildFailed, Output: "undefined: x"})
+	require.NotNil(t, tc.Failure)
+	assert.Equal(t, "undefined: x", tc.Failure.Text)
+
+	tc = cp.coverageTestCase(reporter.Coverage{PackageName: "example.com/mod/untested", Status: reporter.StatusNoTestFiles})
+	assert.Nil(t, tc.Failure)
+	assert.NotNil(t, tc.Skipped)
+
+	cp.Reporter.UntestedPolicy = reporter.UntestedFail
+	tc = cp.coverageTestCase(reporter.Coverage{PackageName: "example.com/mod/untested", Status: reporter.StatusNoTestFiles})
+	require.NotNil(t, tc.Failure)
+	assert.Equal(t, "package has no test files (untested policy: fail)", tc.Failure.Message)
+}
+
+func TestGoTestSuite(t *testing.T) {
+	suite := goTestSuite(reporter.Coverage{
+		PackageName: "example.com/mod/pkg",
+		Status:      reporter.StatusTestFailed,
+		Tests: []reporter.TestResult{
+			{Name: "TestA", Outcome: reporter.TestPass, Elapsed: 0.5},
+			{Name: "TestB", Outcome: reporter.TestFail, Elapsed: 0.25},
+			{Name: "TestB/sub", Outcome: reporter.TestFail, Elapsed: 0.25},
+			{Name: "TestC", Outcome: reporter.TestSkip},
+		},
+	})
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	assert.Equal(t, "0.750", suite.Time)
+
+	suite = goTestSuite(reporter.Coverage{PackageName: "example.com/mod/pkg", Status: reporter.StatusBuildFailed, Output: "undefined: x"})
+	assert.Equal(t, 1, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	require.Len(t, suite.Cases, 1)
+	assert.Equal(t, "undefined: x", suite.Cases[0].Failure.Text)
+
+	var report junitTestSuites
+	report.add(suite)
+	report.add(junitTestSuite{Tests: 2, Skipped: 1})
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Skipped)
+}
@@ -0,0 +1,169 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+)
+
+// Limits keeping the report below the 65,536 characters GitHub accepts in a
+// comment: the tail of the go test output shown for a failing package, and
+// the length after which the details of further failing packages are left out
+const (
+	MarkdownOutputLines = 40
+	MarkdownOutputChars = 4000
+	MarkdownMaxChars    = 60000
+)
+
+// Emoji marking passing, failing and ignored packages in Markdown output
+const (
+	markdownPassed  = "✅"
+	markdownFailed  = "❌"
+	markdownIgnored = "⚪"
+)
+
+// PrintCoverageMarkdown prints the coverage data as GitHub-flavoured
+// Markdown, suitable for job summaries and pull request comments. Failing
+// packages get a collapsed section with their test output or their
+// least-covered functions.
+func (cp *CoveragePrinter) PrintCoverageMarkdown(coverages []reporter.Coverage) error {
+	var sb strings.Builder
+	summary := cp.Reporter.Summarize(coverages)
+
+	icon := markdownPassed
+	if summary.Failed > 0 || !summary.GlobalPassed {
+		icon = markdownFailed
+	}
+	fmt.Fprintf(&sb, "## %s Coverage report\n\n", icon)
+	fmt.Fprintf(&sb, "**Total coverage: %.2f%%** (%d/%d statements)", summary.Total.Percentage(), summary.Total.Covered, summary.Total.Statements)
+	if cp.Reporter.GlobalThreshold > 0 {
+		fmt.Fprintf(&sb, ", global threshold %.2f%%", cp.Reporter.GlobalThreshold)
+	}
+	fmt.Fprintf(&sb, " · %d passed, %d failed\n\n", summary.Passed, summary.Failed)
+
+	withBaseline := cp.Reporter.Baseline != nil
+	sb.WriteString("| | Package | Coverage | Threshold |")
+	if withBaseline {
+		sb.WriteString(" Δ Baseline |")
+	}
+	sb.WriteString(" Status |\n|:-:|:--|--:|--:|")
+	if withBaseline {
+		sb.WriteString("--:|")
+	}
+	sb.WriteString(":--|\n")
+
+	var failing []reporter.Coverage
+	for _, cov := range coverages {
+		icon := markdownPassed
+		switch {
+		case cov.Untested() && cp.Reporter.UntestedPolicy == reporter.UntestedIgnore:
+			icon = markdownIgnored
+		case !cp.Reporter.Passed(cov):
+			icon = markdownFailed
+			failing = append(failing, cov)
+		}
+
+		fmt.Fprintf(&sb, "| %s | `%s` | %.2f%% | %.2f%% |", icon, cov.PackageName, cov.Percentage, cp.Reporter.PackageThreshold(cov.PackageName))
+		if withBaseline {
+			fmt.Fprintf(&sb, " %s |", markdownDelta(cp.Reporter.BaselineDelta(cov)))
+		}
+		fmt.Fprintf(&sb, " %s |\n", cp.statusText(cov))
+	}
+
+	if cp.Reporter.Base != "" {
+		patch := reporter.OverallPatchCoverage(coverages)
+		icon := markdownPassed
+		if !cp.Reporter.PatchPassed(patch) {
+			icon = markdownFailed
+		}
+		fmt.Fprintf(&sb, "\n%s **Patch coverage against `%s`: %.2f%%** (%d/%d changed lines, threshold %.2f%%)\n",
+			icon, cp.Reporter.Base, patch.Percentage(), patch.Covered, patch.Lines, cp.Reporter.PatchThreshold)
+	}
+
+	if len(failing) > 0 {
+		fmt.Fprintf(&sb, "\n### Failing packages (%d)\n", len(failing))
+		for i, cov := range failing {
+			var details strings.Builder
+			cp.writeMarkdownDetails(&details, cov)
+			if sb.Len()+details.Len() > MarkdownMaxChars {
+				fmt.Fprintf(&sb, "\nDetails of %d more failing packages are omitted.\n", len(failing)-i)
+				break
+			}
+			sb.WriteString(details.String())
+		}
+	}
+
+	_, err := fmt.Fprint(cp.Output, sb.String())
+	return err
+}
+
+// writeMarkdownDetails writes a collapsed section explaining why the package failed
+func (cp *CoveragePrinter) writeMarkdownDetails(sb *strings.Builder, cov reporter.Coverage) {
+	fmt.Fprintf(sb, "\n<details>\n<summary><code>%s</code>: %.2f%% (threshold %.2f%%, %s)</summary>\n\n",
+		cov.PackageName, cov.Percentage, cp.Reporter.PackageThreshold(cov.PackageName), cp.statusText(cov))
+
+	switch funcs := cov.LeastCoveredFunctions(DetailFunctionLimit); {
+	case cov.Status.Failed():
+		sb.WriteString(markdownOutputBlock(cov.Output))
+	case cov.Untested():
+		fmt.Fprintf(sb, "The package has no test files (untested policy: %s).\n", cp.Reporter.UntestedPolicy)
+	case len(funcs) > 0:
+		sb.WriteString("| Function | Location | Statements | Coverage |\n|:--|:--|--:|--:|\n")
+		for _, fn := range funcs {
+			fmt.Fprintf(sb, "| `%s` | `%s:%d` | %d/%d | %.2f%% |\n",
+				fn.Name, cp.Reporter.Paths.RelPath(fn.FileName), fn.StartLine, fn.Covered, fn.Statements, fn.Percentage())
+		}
+	default:
+		sb.WriteString("No function breakdown is available for this package.\n")
+	}
+
+	sb.WriteString("\n</details>\n")
+}
+
+// markdownOutputBlock renders the tail of the go test output as a code block.
+// The fence is longer than any backtick run in the output so it cannot be
+// closed early.
+func markdownOutputBlock(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	omitted := 0
+	if len(lines) > MarkdownOutputLines {
+		omitted = len(lines) - MarkdownOutputLines
+		lines = lines[omitted:]
+	}
+	for len(lines) > 1 && len(strings.Join(lines, "\n")) > MarkdownOutputChars {
+		lines = lines[1:]
+		omitted++
+	}
+	text := strings.Join(lines, "\n")
+	if len(text) > MarkdownOutputChars {
+		text = strings.ToValidUTF8(text[len(text)-MarkdownOutputChars:], "")
+	}
+
+	longest, run := 0, 0
+	for _, r := range text {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+
+	var sb strings.Builder
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "Last %d lines of the output, %d earlier lines omitted:\n\n", len(lines), omitted)
+	}
+	fmt.Fprintf(&sb, "%s\n%s\n%s\n", fence, text, fence)
+	return sb.String()
+}
+
+// markdownDelta formats a baseline delta, highlighting drops
+func markdownDelta(delta float64, ok bool) string {
+	text := deltaText(delta, ok)
+	if ok && strings.HasPrefix(text, "-") {
+		return "🔻 " + text
+	}
+	return text
+}
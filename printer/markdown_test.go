@@ -0,0 +1,26 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownOutputBlock(t *testing.T) {
+	assert.Equal(t, "```\nFAIL\n```\n", markdownOutputBlock("FAIL\n"))
+	assert.Equal(t, "````\nwant ```go```\n````\n", markdownOutputBlock("want ```go```"))
+
+	var lines []string
+	for i := 1; i <= MarkdownOutputLines+5; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	block := markdownOutputBlock(strings.Join(lines, "\n"))
+	assert.True(t, strings.HasPrefix(block, fmt.Sprintf("Last %d lines of the output, 5 earlier lines omitted:", MarkdownOutputLines)))
+	assert.NotContains(t, block, "line 5\n")
+	assert.Contains(t, block, "line 6\n")
+
+	block = markdownOutputBlock(strings.Repeat("x", 3*MarkdownOutputChars))
+	assert.Len(t, block, MarkdownOutputChars+len("```\n\n```\n"))
+}
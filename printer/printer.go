@@ -1,14 +1,101 @@
 package printer
 
 import (
-	"github.com/mkabdelrahman/coverco/reporter"
+	"fmt"
 	"io"
+	"math"
+	"sort"
+
+	"github.com/mkabdelrahman/coverco/reporter"
 )
 
+// Printer writes coverage results in a specific output format
+type Printer interface {
+	Print(coverages []reporter.Coverage) error
+}
+
+// PrinterFunc adapts an ordinary function to the Printer interface
+type PrinterFunc func(coverages []reporter.Coverage) error
+
+// Print calls f(coverages)
+func (f PrinterFunc) Print(coverages []reporter.Coverage) error {
+	return f(coverages)
+}
+
+// Factory creates a Printer that writes to the output of the given CoveragePrinter
+type Factory func(cp *CoveragePrinter) Printer
+
+// formats holds the registered output formats by name
+var formats = map[string]Factory{
+	"table": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(func(coverages []reporter.Coverage) error {
+			cp.PrintCoverageTable(coverages)
+			return nil
+		})
+	},
+	"tree": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(func(coverages []reporter.Coverage) error {
+			cp.PrintCoverageTree(coverages)
+			return nil
+		})
+	},
+	"html": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageHTML)
+	},
+	"badge": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageBadge)
+	},
+	"markdown": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageMarkdown)
+	},
+	"github": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintGitHubAnnotations)
+	},
+	"gitlab": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintGitLabCodeQuality)
+	},
+	"junit": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageJUnit)
+	},
+	"csv": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageCSV)
+	},
+	"json": func(cp *CoveragePrinter) Printer {
+		return PrinterFunc(cp.PrintCoverageJSON)
+	},
+}
+
+// RegisterFormat registers a printer factory under the given format name,
+// replacing any existing factory with the same name
+func RegisterFormat(name string, factory Factory) {
+	formats[name] = factory
+}
+
+// Formats returns the names of all registered output formats
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options controls optional sections of the printed output
+type Options struct {
+	// Detail lists the least-covered functions of every failing package
+	Detail bool
+	// Delta shows the change in coverage since the previous recorded run
+	Delta bool
+	// BadgeDir receives an SVG badge per package from the badge output
+	BadgeDir string
+}
+
 // CoveragePrinter handles printing of coverage results
 type CoveragePrinter struct {
 	Reporter *reporter.CoverageReporter
 	Output   io.Writer
+	Options  Options
 }
 
 // NewCoveragePrinter creates a new CoveragePrinter instance
@@ -18,3 +105,51 @@ func NewCoveragePrinter(reporter *reporter.CoverageReporter, output io.Writer) *
 		Output:   output,
 	}
 }
+
+// NewPrinter creates a Printer for the named format writing to output
+func NewPrinter(format string, reporter *reporter.CoverageReporter, output io.Writer, opts Options) (Printer, error) {
+	factory, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (supported: %v)", format, Formats())
+	}
+	cp := NewCoveragePrinter(reporter, output)
+	cp.Options = opts
+	return factory(cp), nil
+}
+
+// statusText describes the go test status of the package, noting when its
+// tests passed but its coverage is below its threshold
+func (cp *CoveragePrinter) statusText(cov reporter.Coverage) string {
+	if cov.Status == reporter.StatusPassed && !cp.Reporter.Passed(cov) {
+		return "passed, coverage below threshold"
+	}
+	return string(cov.Status)
+}
+
+// globalThresholdText formats the global threshold, or "-" when it is disabled
+func (cp *CoveragePrinter) globalThresholdText() string {
+	if cp.Reporter.GlobalThreshold == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", cp.Reporter.GlobalThreshold)
+}
+
+// deltaText formats a change in coverage in percentage points, or "new" when
+// there is nothing to compare against
+func deltaText(delta float64, ok bool) string {
+	if !ok {
+		return "new"
+	}
+	if math.Abs(delta) < 0.005 {
+		delta = 0
+	}
+	return fmt.Sprintf("%+.2f", delta)
+}
+
+// previousTotal returns the total coverage of the previous run, or 0 without history
+func previousTotal(previous *reporter.HistoryEntry) float64 {
+	if previous == nil {
+		return 0
+	}
+	return previous.Total
+}
@@ -0,0 +1,35 @@
+package printer
+
+import (
+	"io"
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusText(t *testing.T) {
+	cp := &CoveragePrinter{Reporter: &reporter.CoverageReporter{Packages: []finder.Package{
+		{Name: "example.com/mod/pkg", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+	}}}
+
+	assert.Equal(t, "passed", cp.statusText(reporter.Coverage{PackageName: "example.com/mod/pkg", Status: reporter.StatusPassed, Percentage: 60}))
+	assert.Equal(t, "passed, coverage below threshold", cp.statusText(reporter.Coverage{PackageName: "example.com/mod/pkg", Status: reporter.StatusPassed, Percentage: 40}))
+	assert.Equal(t, "test-failed", cp.statusText(reporter.Coverage{PackageName: "example.com/mod/pkg", Status: reporter.StatusTestFailed, Percentage: 40}))
+}
+
+func TestNewPrinter(t *testing.T) {
+	cr := &reporter.CoverageReporter{}
+
+	for _, format := range Formats() {
+		p, err := NewPrinter(format, cr, io.Discard, Options{})
+		require.NoError(t, err, format)
+		assert.NotNil(t, p, format)
+	}
+
+	_, err := NewPrinter("bogus", cr, io.Discard, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown output format "bogus"`)
+}
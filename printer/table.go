@@ -3,47 +3,155 @@ package printer
 import (
 	"github.com/mkabdelrahman/coverco/reporter"
 	"fmt"
-	"os"
 
 	"github.com/olekukonko/tablewriter"
 )
 
 // PrintCoverageTable prints the coverage data as a table
 func (cp *CoveragePrinter) PrintCoverageTable(coverages []reporter.Coverage) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Package Name", "Coverage Percentage", "Threshold"})
+	table := tablewriter.NewWriter(cp.Output)
+	header := []string{"Package Name", "Coverage Percentage", "Threshold", "Status"}
+	if cp.Options.Delta {
+		header = append(header, "Delta")
+	}
+	table.SetHeader(header)
 
-	for _, cov := range coverages {
-		packageThreshold := cp.Reporter.DefaultCoverageThreshold
-		for _, pkg := range cp.Reporter.Packages {
-			if pkg.Name == cov.PackageName {
-				packageThreshold = pkg.Threshold
-				break
-			}
-		}
+	tested, untested := cp.Reporter.SplitUntested(coverages)
+	for _, cov := range tested {
+		packageThreshold := cp.Reporter.PackageThreshold(cov.PackageName)
 
 		row := []string{
 			cov.PackageName,
 			fmt.Sprintf("%.2f%%", cov.Percentage),
 			fmt.Sprintf("%.2f%%", packageThreshold),
+			cp.statusText(cov),
+		}
+		if cp.Options.Delta {
+			row = append(row, deltaText(cp.Reporter.PreviousDelta(cov)))
 		}
 
-		if cov.Percentage < float64(packageThreshold) {
-			// Set text color to red for packages that do not meet the threshold
-			table.Rich(row, []tablewriter.Colors{
-				{tablewriter.FgRedColor},
-				{tablewriter.FgRedColor},
-				{tablewriter.FgRedColor},
-			})
-		} else {
-			// Set text color to green for packages that meet or exceed the threshold
-			table.Rich(row, []tablewriter.Colors{
-				{tablewriter.FgGreenColor},
-				{tablewriter.FgGreenColor},
-				{tablewriter.FgGreenColor},
+		// Set text color to red for packages that failed or do not meet the threshold,
+		// and to green for packages that meet or exceed the threshold
+		color := tablewriter.FgGreenColor
+		if !cp.Reporter.Passed(cov) {
+			color = tablewriter.FgRedColor
+		}
+		colors := make([]tablewriter.Colors, len(row))
+		for i := range colors {
+			colors[i] = tablewriter.Colors{color}
+		}
+		table.Rich(row, colors)
+	}
+
+	summary := cp.Reporter.Summarize(coverages)
+	footer := []string{
+		"Total",
+		fmt.Sprintf("%.2f%% (%d/%d)", summary.Total.Percentage(), summary.Total.Covered, summary.Total.Statements),
+		cp.globalThresholdText(),
+		fmt.Sprintf("%d passed, %d failed", summary.Passed, summary.Failed),
+	}
+	footerColor := tablewriter.FgGreenColor
+	if !summary.GlobalPassed {
+		footerColor = tablewriter.FgRedColor
+	}
+	footerColors := []tablewriter.Colors{{tablewriter.Bold}, {footerColor}, {}, {}}
+	if cp.Options.Delta {
+		previous := cp.Reporter.Previous
+		footer = append(footer, deltaText(summary.Total.Percentage()-previousTotal(previous), previous != nil))
+		footerColors = append(footerColors, tablewriter.Colors{})
+	}
+	table.SetFooter(footer)
+	table.SetFooterColor(footerColors...)
+	table.Render()
+
+	if len(untested) > 0 {
+		cp.printUntestedTable(untested)
+	}
+
+	if cp.Reporter.Base != "" {
+		cp.printPatchTable(coverages)
+	}
+
+	if cp.Options.Detail {
+		cp.printFunctionDetail(coverages)
+	}
+}
+
+// DetailFunctionLimit is the number of least-covered functions listed per failing package
+const DetailFunctionLimit = 10
+
+// printFunctionDetail prints the least-covered functions of every failing package
+func (cp *CoveragePrinter) printFunctionDetail(coverages []reporter.Coverage) {
+	for _, cov := range coverages {
+		if cp.Reporter.Passed(cov) {
+			continue
+		}
+		funcs := cov.LeastCoveredFunctions(DetailFunctionLimit)
+		if len(funcs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(cp.Output, "\nLeast covered functions in %s:\n", cov.PackageName)
+		table := tablewriter.NewWriter(cp.Output)
+		table.SetHeader([]string{"Function", "Location", "Statements", "Coverage"})
+		for _, fn := range funcs {
+			table.Append([]string{
+				fn.Name,
+				fmt.Sprintf("%s:%d", cp.Reporter.Paths.RelPath(fn.FileName), fn.StartLine),
+				fmt.Sprintf("%d/%d", fn.Covered, fn.Statements),
+				fmt.Sprintf("%.2f%%", fn.Percentage()),
 			})
 		}
+		table.Render()
 	}
+}
 
+// printUntestedTable prints the packages without test files and how the untested policy judged them
+func (cp *CoveragePrinter) printUntestedTable(untested []reporter.Coverage) {
+	fmt.Fprintf(cp.Output, "\nUntested packages (policy: %s):\n", cp.Reporter.UntestedPolicy)
+	table := tablewriter.NewWriter(cp.Output)
+	table.SetHeader([]string{"Package Name", "Coverage Percentage", "Result"})
+	for _, cov := range untested {
+		result, color := "ignored", tablewriter.FgYellowColor
+		if cp.Reporter.UntestedPolicy != reporter.UntestedIgnore {
+			result, color = "passed", tablewriter.FgGreenColor
+			if !cp.Reporter.Passed(cov) {
+				result, color = "failed", tablewriter.FgRedColor
+			}
+		}
+		row := []string{cov.PackageName, fmt.Sprintf("%.2f%%", cov.Percentage), result}
+		table.Rich(row, []tablewriter.Colors{{color}, {color}, {color}})
+	}
+	table.Render()
+}
+
+// printPatchTable prints the coverage of the lines changed since the base ref
+func (cp *CoveragePrinter) printPatchTable(coverages []reporter.Coverage) {
+	fmt.Fprintf(cp.Output, "\nPatch coverage against %s:\n", cp.Reporter.Base)
+	table := tablewriter.NewWriter(cp.Output)
+	table.SetHeader([]string{"Package Name", "Changed Lines", "Patch Coverage", "Threshold"})
+
+	appendRow := func(name string, patch reporter.PatchCoverage) {
+		row := []string{
+			name,
+			fmt.Sprintf("%d/%d", patch.Covered, patch.Lines),
+			fmt.Sprintf("%.2f%%", patch.Percentage()),
+			fmt.Sprintf("%.2f%%", cp.Reporter.PatchThreshold),
+		}
+		color := tablewriter.FgGreenColor
+		if !cp.Reporter.PatchPassed(patch) {
+			color = tablewriter.FgRedColor
+		}
+		table.Rich(row, []tablewriter.Colors{{color}, {color}, {color}, {color}})
+	}
+
+	for _, cov := range coverages {
+		if cov.Patch == nil || cov.Patch.Lines == 0 {
+			continue
+		}
+		appendRow(cov.PackageName, *cov.Patch)
+	}
+	overall := reporter.OverallPatchCoverage(coverages)
+	table.SetFooter([]string{"Total", fmt.Sprintf("%d/%d", overall.Covered, overall.Lines), fmt.Sprintf("%.2f%%", overall.Percentage()), ""})
 	table.Render()
 }
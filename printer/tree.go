@@ -0,0 +1,89 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mkabdelrahman/coverco/reporter"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PrintCoverageTree prints the coverage data grouped by import path, with
+// the statement-weighted coverage of every directory. Directories are judged
+// by their subtree threshold only; a directory that is also a package gets a
+// separate row for the package itself.
+func (cp *CoveragePrinter) PrintCoverageTree(coverages []reporter.Coverage) {
+	table := tablewriter.NewWriter(cp.Output)
+	table.SetHeader([]string{"Path", "Coverage Percentage", "Statements", "Threshold", "Status"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	cp.Reporter.Tree(coverages).Walk(func(node *reporter.TreeNode, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if len(node.Children) == 0 && node.Coverage != nil {
+			cp.addTreePackageRow(table, indent+node.Name, *node.Coverage, node.Threshold)
+			return
+		}
+
+		threshold, status := "-", ""
+		if node.Threshold > 0 {
+			threshold = fmt.Sprintf("%.2f%% (subtree)", node.Threshold)
+			status = "passed"
+			if !node.Passed() {
+				status = "failed"
+			}
+		}
+		addTreeRow(table, node.Passed(), indent+node.Name+"/", node.Totals.Percentage(), node.Totals, threshold, status)
+		if node.Coverage != nil {
+			cp.addTreePackageRow(table, indent+"  (package)", *node.Coverage, 0)
+		}
+	})
+
+	summary := cp.Reporter.Summarize(coverages)
+	footerColor := tablewriter.FgGreenColor
+	if !summary.GlobalPassed {
+		footerColor = tablewriter.FgRedColor
+	}
+	table.SetFooter([]string{
+		"Total",
+		fmt.Sprintf("%.2f%%", summary.Total.Percentage()),
+		fmt.Sprintf("%d/%d", summary.Total.Covered, summary.Total.Statements),
+		cp.globalThresholdText(),
+		fmt.Sprintf("%d passed, %d failed", summary.Passed, summary.Failed),
+	})
+	table.SetFooterColor(tablewriter.Colors{tablewriter.Bold}, tablewriter.Colors{footerColor}, tablewriter.Colors{}, tablewriter.Colors{}, tablewriter.Colors{})
+	table.Render()
+}
+
+// addTreePackageRow adds the row of a package, checked against its own
+// threshold and against the subtree threshold of a pattern naming it
+func (cp *CoveragePrinter) addTreePackageRow(table *tablewriter.Table, name string, cov reporter.Coverage, subtreeThreshold float64) {
+	totals := reporter.Totals{Statements: cov.Statements, Covered: cov.CoveredStatements}
+	threshold := fmt.Sprintf("%.2f%%", cp.Reporter.PackageThreshold(cov.PackageName))
+	passed := cp.Reporter.Passed(cov)
+	if subtreeThreshold > 0 {
+		threshold += fmt.Sprintf(", %.2f%% (subtree)", subtreeThreshold)
+		passed = passed && totals.Percentage() >= subtreeThreshold
+	}
+	addTreeRow(table, passed, name, cov.Percentage, totals, threshold, cp.statusText(cov))
+}
+
+// addTreeRow adds a row colored by its verdict
+func addTreeRow(table *tablewriter.Table, passed bool, name string, percentage float64, totals reporter.Totals, threshold, status string) {
+	row := []string{
+		name,
+		fmt.Sprintf("%.2f%%", percentage),
+		fmt.Sprintf("%d/%d", totals.Covered, totals.Statements),
+		threshold,
+		status,
+	}
+
+	color := tablewriter.FgGreenColor
+	if !passed {
+		color = tablewriter.FgRedColor
+	}
+	colors := make([]tablewriter.Colors, len(row))
+	for i := range colors {
+		colors[i] = tablewriter.Colors{color}
+	}
+	table.Rich(row, colors)
+}
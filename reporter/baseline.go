@@ -0,0 +1,154 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// BaselineSchemaVersion is the version of the baseline file format
+const BaselineSchemaVersion = 1
+
+// Baseline records the coverage of every package at a point in time. It is
+// committed to the repository so coverage can only go up.
+type Baseline struct {
+	SchemaVersion int                `json:"schema_version"`
+	Timestamp     time.Time          `json:"timestamp"`
+	GitCommit     string             `json:"git_commit,omitempty"`
+	Packages      map[string]float64 `json:"packages"`
+}
+
+// BaselineRegression is a package whose coverage dropped below its baseline
+type BaselineRegression struct {
+	PackageName string
+	Baseline    float64
+	Current     float64
+}
+
+// Drop returns how many percentage points the coverage dropped
+func (r BaselineRegression) Drop() float64 {
+	return r.Baseline - r.Current
+}
+
+// LoadBaseline reads the baseline file. A missing file yields an error
+// wrapping os.ErrNotExist.
+func LoadBaseline(fileName string) (*Baseline, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", fileName, err)
+	}
+	if baseline.SchemaVersion > BaselineSchemaVersion {
+		return nil, fmt.Errorf("baseline %s has unsupported schema version %d", fileName, baseline.SchemaVersion)
+	}
+	if baseline.Packages == nil {
+		baseline.Packages = make(map[string]float64)
+	}
+	return &baseline, nil
+}
+
+// NewBaseline records the coverage of the packages whose tests ran
+// successfully. Packages left out of this run, e.g. by -changed-since or
+// package filters, keep their entries of the loaded baseline.
+func (cr *CoverageReporter) NewBaseline(coverages []Coverage) *Baseline {
+	baseline := &Baseline{Packages: make(map[string]float64)}
+	if cr.Baseline != nil {
+		for name, percentage := range cr.Baseline.Packages {
+			baseline.Packages[name] = percentage
+		}
+	}
+	for _, cov := range coverages {
+		if !cov.Status.Failed() {
+			baseline.Packages[cov.PackageName] = roundPercentage(cov.Percentage)
+		}
+	}
+	metadata := cr.Metadata()
+	baseline.Timestamp = metadata.Timestamp.UTC()
+	baseline.GitCommit = metadata.GitCommit
+	return baseline
+}
+
+// Raise updates the baseline entries of packages whose coverage improved and
+// adds packages missing from the baseline. Packages whose tests failed are
+// left untouched. It reports whether the baseline changed.
+func (cr *CoverageReporter) Raise(baseline *Baseline, coverages []Coverage) bool {
+	changed := false
+	for _, cov := range coverages {
+		if cov.Status.Failed() {
+			continue
+		}
+		current := roundPercentage(cov.Percentage)
+		if previous, ok := baseline.Packages[cov.PackageName]; !ok || current > previous {
+			baseline.Packages[cov.PackageName] = current
+			changed = true
+		}
+	}
+	if changed {
+		metadata := cr.Metadata()
+		baseline.Timestamp = metadata.Timestamp.UTC()
+		baseline.GitCommit = metadata.GitCommit
+	}
+	return changed
+}
+
+// Write stores the baseline as indented JSON
+func (b *Baseline) Write(fileName string) error {
+	b.SchemaVersion = BaselineSchemaVersion
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(fileName, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// BaselineDelta returns the change in coverage of the package since the
+// baseline, and whether the package has a baseline entry
+func (cr *CoverageReporter) BaselineDelta(cov Coverage) (float64, bool) {
+	if cr.Baseline == nil {
+		return 0, false
+	}
+	previous, ok := cr.Baseline.Packages[cov.PackageName]
+	if !ok {
+		return 0, false
+	}
+	return roundPercentage(cov.Percentage) - previous, true
+}
+
+// BaselineRegressions returns the packages whose coverage dropped more than
+// the baseline tolerance below their baseline, sorted by package name.
+// Packages whose tests failed are reported as test failures instead.
+func (cr *CoverageReporter) BaselineRegressions(coverages []Coverage) []BaselineRegression {
+	var regressions []BaselineRegression
+	for _, cov := range coverages {
+		if cov.Status.Failed() {
+			continue
+		}
+		if delta, ok := cr.BaselineDelta(cov); ok && -delta > cr.BaselineTolerance {
+			regressions = append(regressions, BaselineRegression{
+				PackageName: cov.PackageName,
+				Baseline:    cr.Baseline.Packages[cov.PackageName],
+				Current:     roundPercentage(cov.Percentage),
+			})
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].PackageName < regressions[j].PackageName
+	})
+	return regressions
+}
+
+// roundPercentage rounds a percentage to two decimals so baselines stay
+// stable across runs
+func roundPercentage(p float64) float64 {
+	return math.Round(p*100) / 100
+}
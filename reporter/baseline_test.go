@@ -0,0 +1,63 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ".coverco-baseline.json")
+	_, err := LoadBaseline(fileName)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	cr := &CoverageReporter{BaselineTolerance: 0.5}
+	baseline := cr.NewBaseline([]Coverage{
+		{PackageName: "example.com/mod/a", Percentage: 80.004, Status: StatusPassed},
+		{PackageName: "example.com/mod/b", Percentage: 60, Status: StatusPassed},
+		{PackageName: "example.com/mod/c", Percentage: 10, Status: StatusTestFailed},
+	})
+	require.NoError(t, baseline.Write(fileName))
+
+	cr.Baseline, err = LoadBaseline(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"example.com/mod/a": 80, "example.com/mod/b": 60}, cr.Baseline.Packages)
+
+	coverages := []Coverage{
+		{PackageName: "example.com/mod/a", Percentage: 79.6, Status: StatusPassed},
+		{PackageName: "example.com/mod/b", Percentage: 59.4, Status: StatusPassed},
+		{PackageName: "example.com/mod/d", Percentage: 5, Status: StatusPassed},
+	}
+	regressions := cr.BaselineRegressions(coverages)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, "example.com/mod/b", regressions[0].PackageName)
+	assert.InDelta(t, 0.6, regressions[0].Drop(), 1e-9)
+
+	delta, ok := cr.BaselineDelta(coverages[0])
+	assert.True(t, ok)
+	assert.InDelta(t, -0.4, delta, 1e-9)
+	_, ok = cr.BaselineDelta(coverages[2])
+	assert.False(t, ok)
+
+	coverages[1].Percentage = 65
+	assert.True(t, cr.Raise(cr.Baseline, coverages))
+	assert.Equal(t, map[string]float64{"example.com/mod/a": 80, "example.com/mod/b": 65, "example.com/mod/d": 5}, cr.Baseline.Packages)
+	assert.False(t, cr.Raise(cr.Baseline, coverages))
+}
+
+func TestNewBaselineKeepsUntestedEntries(t *testing.T) {
+	cr := &CoverageReporter{Baseline: &Baseline{Packages: map[string]float64{
+		"example.com/mod/a": 80,
+		"example.com/mod/b": 60,
+	}}}
+
+	baseline := cr.NewBaseline([]Coverage{
+		{PackageName: "example.com/mod/a", Percentage: 70, Status: StatusPassed},
+		{PackageName: "example.com/mod/c", Percentage: 50, Status: StatusPassed},
+	})
+	assert.Equal(t, map[string]float64{"example.com/mod/a": 70, "example.com/mod/b": 60, "example.com/mod/c": 50}, baseline.Packages)
+	assert.Equal(t, 80.0, cr.Baseline.Packages["example.com/mod/a"], "the loaded baseline is not modified")
+}
@@ -0,0 +1,101 @@
+package reporter
+
+import (
+	"sort"
+)
+
+// FileCoverage holds the statement counts of a single source file
+type FileCoverage struct {
+	FileName   string
+	Statements int
+	Covered    int
+	Functions  []FunctionCoverage
+}
+
+// FunctionCoverage holds the statement counts of a single function
+type FunctionCoverage struct {
+	Name       string
+	FileName   string
+	StartLine  int
+	Statements int
+	Covered    int
+}
+
+// Percentage returns the percentage of covered statements in the file
+func (fc FileCoverage) Percentage() float64 {
+	return percentage(fc.Covered, fc.Statements)
+}
+
+// Percentage returns the percentage of covered statements in the function
+func (fc FunctionCoverage) Percentage() float64 {
+	return percentage(fc.Covered, fc.Statements)
+}
+
+// LeastCoveredFunctions returns up to limit functions of the package with
+// uncovered statements, ordered by ascending coverage and then by the number
+// of uncovered statements
+func (c Coverage) LeastCoveredFunctions(limit int) []FunctionCoverage {
+	var funcs []FunctionCoverage
+	for _, file := range c.Files {
+		for _, fn := range file.Functions {
+			if fn.Covered < fn.Statements {
+				funcs = append(funcs, fn)
+			}
+		}
+	}
+
+	sort.SliceStable(funcs, func(i, j int) bool {
+		pi, pj := funcs[i].Percentage(), funcs[j].Percentage()
+		if pi != pj {
+			return pi < pj
+		}
+		return funcs[i].Statements-funcs[i].Covered > funcs[j].Statements-funcs[j].Covered
+	})
+
+	if limit > 0 && len(funcs) > limit {
+		funcs = funcs[:limit]
+	}
+	return funcs
+}
+
+// fileCoverages computes the per-file and per-function statement counts of
+// the profiles. Functions are omitted for sources that cannot be read.
+func fileCoverages(profiles []*Profile, paths *PathResolver) []FileCoverage {
+	files := make([]FileCoverage, 0, len(profiles))
+	for _, profile := range profiles {
+		file := FileCoverage{FileName: profile.FileName}
+		for _, b := range profile.Blocks {
+			file.Statements += b.NumStmt
+			if b.Count > 0 {
+				file.Covered += b.NumStmt
+			}
+		}
+
+		if funcs, err := FindFuncs(paths.AbsPath(profile.FileName)); err == nil {
+			for _, fn := range funcs {
+				fnCoverage := FunctionCoverage{Name: fn.Name, FileName: profile.FileName, StartLine: fn.StartLine}
+				for _, b := range profile.Blocks {
+					if !fn.contains(b) {
+						continue
+					}
+					fnCoverage.Statements += b.NumStmt
+					if b.Count > 0 {
+						fnCoverage.Covered += b.NumStmt
+					}
+				}
+				file.Functions = append(file.Functions, fnCoverage)
+			}
+		}
+
+		files = append(files, file)
+	}
+	return files
+}
+
+// percentage returns covered/total as a percentage, or zero when there is nothing to cover
+func percentage(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeastCoveredFunctions(t *testing.T) {
+	cov := Coverage{Files: []FileCoverage{
+		{Functions: []FunctionCoverage{
+			{Name: "full", Statements: 4, Covered: 4},
+			{Name: "half", Statements: 4, Covered: 2},
+			{Name: "none", Statements: 2, Covered: 0},
+		}},
+		{Functions: []FunctionCoverage{
+			{Name: "bigNone", Statements: 10, Covered: 0},
+		}},
+	}}
+
+	var names []string
+	for _, fn := range cov.LeastCoveredFunctions(2) {
+		names = append(names, fn.Name)
+	}
+	assert.Equal(t, []string{"bigNone", "none"}, names)
+	assert.Len(t, cov.LeastCoveredFunctions(0), 3)
+}
+
+func TestApplyProfilesPercentage(t *testing.T) {
+	cr := &CoverageReporter{}
+	cov := Coverage{PackageName: "example.com/mod/pkg", Percentage: 66.7}
+	cr.applyProfiles(&cov, []*Profile{
+		{FileName: "example.com/mod/pkg/a.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 1},
+			{StartLine: 7, StartCol: 10, EndLine: 9, EndCol: 2, NumStmt: 1, Count: 0},
+		}},
+	})
+
+	assert.Equal(t, 3, cov.Statements)
+	assert.Equal(t, 2, cov.CoveredStatements)
+	assert.Equal(t, 200.0/3, cov.Percentage)
+}
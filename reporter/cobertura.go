@@ -0,0 +1,176 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"time"
+)
+
+// CoberturaFileName is the name of the combined Cobertura report in the reports directory
+const CoberturaFileName = "coverage.xml"
+
+const coberturaDoctype = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity float64           `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// WriteCobertura writes the profiles as a Cobertura XML report. Files are
+// grouped into packages by import path and reported as classes. Go cover
+// profiles carry no branch information, so branch rates are always zero.
+func WriteCobertura(w io.Writer, profiles []*Profile, paths *PathResolver, timestamp time.Time) error {
+	report := coberturaCoverage{
+		Version:   "coverco",
+		Timestamp: timestamp.UnixMilli(),
+	}
+	if paths != nil {
+		report.Sources = []string{paths.RootDir}
+	}
+
+	packages := make(map[string]*coberturaPackage)
+	packageCounts := make(map[string][2]int)
+	for _, profile := range profiles {
+		pkgName := path.Dir(profile.FileName)
+		pkg, ok := packages[pkgName]
+		if !ok {
+			pkg = &coberturaPackage{Name: pkgName}
+			packages[pkgName] = pkg
+		}
+
+		class, covered, valid := coberturaClassFor(profile, paths)
+		pkg.Classes = append(pkg.Classes, class)
+
+		counts := packageCounts[pkgName]
+		packageCounts[pkgName] = [2]int{counts[0] + covered, counts[1] + valid}
+		report.LinesCovered += covered
+		report.LinesValid += valid
+	}
+
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := packages[name]
+		pkg.LineRate = rate(packageCounts[name][0], packageCounts[name][1])
+		report.Packages = append(report.Packages, *pkg)
+	}
+	report.LineRate = rate(report.LinesCovered, report.LinesValid)
+
+	if _, err := io.WriteString(w, xml.Header+coberturaDoctype+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// coberturaClassFor builds the class of a single source file and returns it
+// along with its covered and valid line counts
+func coberturaClassFor(profile *Profile, paths *PathResolver) (coberturaClass, int, int) {
+	lines := LineCounts(profile)
+	class := coberturaClass{
+		Name:     path.Base(profile.FileName),
+		Filename: paths.RelPath(profile.FileName),
+		Lines:    coberturaLines(lines, 0, 0),
+	}
+
+	if funcs, err := FindFuncs(paths.AbsPath(profile.FileName)); err == nil {
+		for _, fn := range funcs {
+			methodLines := coberturaLines(lines, fn.StartLine, fn.EndLine)
+			class.Methods = append(class.Methods, coberturaMethod{
+				Name:     fn.Name,
+				LineRate: rate(coveredLines(methodLines), len(methodLines)),
+				Lines:    methodLines,
+			})
+		}
+	}
+
+	covered := coveredLines(class.Lines)
+	class.LineRate = rate(covered, len(class.Lines))
+	return class, covered, len(class.Lines)
+}
+
+// coberturaLines returns the sorted lines within [from, to]; a zero range selects all lines
+func coberturaLines(lines map[int]int, from, to int) []coberturaLine {
+	var result []coberturaLine
+	for number, hits := range lines {
+		if to > 0 && (number < from || number > to) {
+			continue
+		}
+		result = append(result, coberturaLine{Number: number, Hits: hits})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+	return result
+}
+
+// coveredLines returns the number of lines that were executed at least once
+func coveredLines(lines []coberturaLine) int {
+	covered := 0
+	for _, line := range lines {
+		if line.Hits > 0 {
+			covered++
+		}
+	}
+	return covered
+}
+
+// rate returns covered/valid, or zero when there is nothing to cover
+func rate(covered, valid int) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return float64(covered) / float64(valid)
+}
@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCobertura(t *testing.T) {
+	profiles := []*Profile{
+		{FileName: "example.com/mod/pkg/a.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 1},
+			{StartLine: 4, StartCol: 2, EndLine: 5, EndCol: 3, NumStmt: 1, Count: 0},
+		}},
+		{FileName: "example.com/mod/pkg/b.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 20, NumStmt: 1, Count: 0},
+		}},
+	}
+	paths := &PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src/mod", RootDir: "/src"}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCobertura(&buf, profiles, paths, time.UnixMilli(1700000000000)))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.5" branch-rate="0" lines-covered="2" lines-valid="4" branches-covered="0" branches-valid="0" complexity="0" version="coverco" timestamp="1700000000000">
+  <sources>
+    <source>/src</source>
+  </sources>
+  <packages>
+    <package name="example.com/mod/pkg" line-rate="0.5" branch-rate="0" complexity="0">
+      <classes>
+        <class name="a.go" filename="mod/pkg/a.go" line-rate="0.6666666666666666" branch-rate="0" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="3" hits="1"></line>
+            <line number="4" hits="1"></line>
+            <line number="5" hits="0"></line>
+          </lines>
+        </class>
+        <class name="b.go" filename="mod/pkg/b.go" line-rate="0" branch-rate="0" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="7" hits="0"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`
+	assert.Equal(t, expected, buf.String())
+}
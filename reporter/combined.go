@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File names of the combined reports in the reports directory
+const (
+	CombinedProfileFileName = "coverage.out"
+	CombinedLcovFileName    = "lcov.info"
+)
+
+// Totals holds the statement counts of the whole module
+type Totals struct {
+	Statements int
+	Covered    int
+}
+
+// Percentage returns the percentage of covered statements
+func (t Totals) Percentage() float64 {
+	return percentage(t.Covered, t.Statements)
+}
+
+// MergedProfiles parses the cover profiles of all tested packages and merges them
+func MergedProfiles(coverages []Coverage) ([]*Profile, error) {
+	var profiles []*Profile
+	for _, cov := range coverages {
+		if cov.ProfileFile == "" {
+			continue
+		}
+		pkgProfiles, err := ParseProfileFile(cov.ProfileFile)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, pkgProfiles...)
+	}
+	return MergeProfiles(profiles), nil
+}
+
+// profileTotals counts the statements of the profiles
+func profileTotals(profiles []*Profile) Totals {
+	var totals Totals
+	for _, profile := range profiles {
+		for _, b := range profile.Blocks {
+			totals.Statements += b.NumStmt
+			if b.Count > 0 {
+				totals.Covered += b.NumStmt
+			}
+		}
+	}
+	return totals
+}
+
+// writeCombinedReports writes the merged profile of all packages to a single
+// coverage.out, along with lcov.info or coverage.xml for those formats
+func (cr *CoverageReporter) writeCombinedReports(profiles []*Profile) error {
+	if err := writeReportFile(filepath.Join(cr.ReportsDir, CombinedProfileFileName), func(f *os.File) error {
+		return WriteProfile(f, profiles)
+	}); err != nil {
+		return err
+	}
+
+	switch cr.OutputFormat {
+	case FormatLcov:
+		return writeReportFile(filepath.Join(cr.ReportsDir, CombinedLcovFileName), func(f *os.File) error {
+			return WriteLcov(f, profiles, cr.Paths)
+		})
+	case FormatCobertura:
+		return writeReportFile(filepath.Join(cr.ReportsDir, CoberturaFileName), func(f *os.File) error {
+			return WriteCobertura(f, profiles, cr.Paths, cr.StartedAt)
+		})
+	}
+	return nil
+}
+
+// writeReportFile creates the named file and fills it using write
+func writeReportFile(name string, write func(f *os.File) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return f.Close()
+}
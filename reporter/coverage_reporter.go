@@ -1,6 +1,7 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,6 +9,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/mkabdelrahman/coverco/finder"
@@ -15,6 +18,14 @@ import (
 
 var (
 	ErrCoveragePercentageNotFound = fmt.Errorf("coverage percentage not found")
+	ErrUnsupportedReportsFormat   = fmt.Errorf("unsupported coverage reports format")
+)
+
+// Supported coverage reports formats
+const (
+	FormatOut       = "out"
+	FormatLcov      = "lcov"
+	FormatCobertura = "cobertura"
 )
 
 // Coverage represents the coverage information for a package
@@ -22,6 +33,20 @@ type Coverage struct {
 	PackageName  string
 	Percentage   float64
 	CoverageFile string
+	ProfileFile  string
+	Status       Status
+	Output       string
+
+	// Statement counts parsed from the cover profile
+	Statements        int
+	CoveredStatements int
+	Files             []FileCoverage
+
+	// Patch holds the coverage of changed lines when a base ref is set
+	Patch *PatchCoverage
+
+	// Tests holds the results of the individual tests when they are recorded
+	Tests []TestResult
 }
 
 // CoverageReporter represents a coverage reporter
@@ -30,73 +55,341 @@ type CoverageReporter struct {
 	DefaultCoverageThreshold float64
 	ReportsDir               string
 	OutputFormat             string
+	KeepReports              bool
+	Parallelism              int
+	Paths                    *PathResolver
+	StartedAt                time.Time
+	Base                     string
+	PatchThreshold           float64
+	UntestedPolicy           string
+	UntestedThreshold        float64
+	CoverPkg                 []string
+	Overall                  Totals
+	GlobalThreshold          float64
+	SubtreeThresholds        map[string]float64
+	Baseline                 *Baseline
+	BaselineTolerance        float64
+	Previous                 *HistoryEntry
+	RecordTests              bool
+	changedLines             ChangedLines
+
+	// testPackage runs the tests of a single package, testSinglePackage unless replaced in tests
+	testPackage func(pkg finder.Package, pkgLog *packageLog) Coverage
+}
+
+// Options configures a CoverageReporter
+type Options struct {
+	ProjectDir               string // directory of the project the packages were found in
+	DefaultCoverageThreshold float64
+	ReportsDir               string
+	OutputFormat             string
+	KeepReports              bool
+	Parallelism              int
+	Base                     string
+	PatchThreshold           float64
+	UntestedPolicy           string
+	UntestedThreshold        float64
+	CoverPkg                 []string // import paths the coverpkg patterns resolved to
+	GlobalThreshold          float64
+	SubtreeThresholds        map[string]float64
+	BaselineFile             string
+	BaselineTolerance        float64
+	RecordTests              bool
 }
 
 // NewCoverageReporter creates a new CoverageReporter instance
-func NewCoverageReporter(packages []finder.Package, defaultThreshold float64, reportsDir, outputFormat string) (*CoverageReporter, error) {
+func NewCoverageReporter(packages []finder.Package, opts Options) (*CoverageReporter, error) {
+	switch opts.OutputFormat {
+	case FormatOut, FormatLcov, FormatCobertura:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedReportsFormat, opts.OutputFormat)
+	}
+	if err := validateUntestedPolicy(opts.UntestedPolicy); err != nil {
+		return nil, err
+	}
 
 	// Ensure the coverage reports directory exists
-	err := ensureDir(reportsDir)
+	err := ensureDir(opts.ReportsDir)
 	if err != nil {
 		return nil, fmt.Errorf("error ensuring coverage reports directory: %s", err.Error())
 	}
 
-	return &CoverageReporter{
+	// Resolve module paths so reports can reference source files on disk
+	paths, err := NewPathResolver(opts.ProjectDir)
+	if err != nil {
+		log.Warnf("Coverage reports will use import paths: %s", err.Error())
+	}
+
+	// Collect the lines changed since the base ref for patch coverage
+	var changedLines ChangedLines
+	if opts.Base != "" {
+		if paths == nil {
+			return nil, fmt.Errorf("patch coverage requires module paths: %w", err)
+		}
+		changedLines, err = GitChangedLines(paths.RootDir, opts.Base)
+		if err != nil {
+			return nil, fmt.Errorf("error computing changed lines: %w", err)
+		}
+	}
+
+	// Load the committed baseline to check for coverage drops
+	var baseline *Baseline
+	if opts.BaselineFile != "" {
+		baseline, err = LoadBaseline(opts.BaselineFile)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debugf("No coverage baseline found at %s", opts.BaselineFile)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	cr := &CoverageReporter{
 		Packages:                 packages,
-		DefaultCoverageThreshold: defaultThreshold,
-		ReportsDir:               reportsDir,
-		OutputFormat:             outputFormat,
-	}, nil
+		DefaultCoverageThreshold: opts.DefaultCoverageThreshold,
+		ReportsDir:               opts.ReportsDir,
+		OutputFormat:             opts.OutputFormat,
+		KeepReports:              opts.KeepReports,
+		Parallelism:              opts.Parallelism,
+		Paths:                    paths,
+		Base:                     opts.Base,
+		PatchThreshold:           opts.PatchThreshold,
+		UntestedPolicy:           opts.UntestedPolicy,
+		UntestedThreshold:        opts.UntestedThreshold,
+		CoverPkg:                 opts.CoverPkg,
+		GlobalThreshold:          opts.GlobalThreshold,
+		SubtreeThresholds:        opts.SubtreeThresholds,
+		Baseline:                 baseline,
+		BaselineTolerance:        opts.BaselineTolerance,
+		RecordTests:              opts.RecordTests,
+		changedLines:             changedLines,
+	}
+
+	// Load the previous run from the history to report deltas
+	history, err := LoadHistory(cr.HistoryFile())
+	if err == nil && len(history) > 0 {
+		cr.Previous = &history[len(history)-1]
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warnf("Ignoring coverage history: %s", err.Error())
+	}
+
+	return cr, nil
 }
 
-// TestPackages tests all packages and returns their coverage information
+// TestPackages tests all packages concurrently using a bounded pool of workers
+// and returns their coverage information in the same order as cr.Packages
 func (cr *CoverageReporter) TestPackages() []Coverage {
-	var coverages []Coverage
-	for _, pkg := range cr.Packages {
-		coverage := cr.testSinglePackage(pkg)
-		coverages = append(coverages, coverage)
+	cr.StartedAt = time.Now()
+
+	workers := cr.Parallelism
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(cr.Packages) {
+		workers = len(cr.Packages)
+	}
+
+	testPackage := cr.testPackage
+	if testPackage == nil {
+		testPackage = cr.testSinglePackage
+	}
+
+	coverages := make([]Coverage, len(cr.Packages))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pkgLog := &packageLog{}
+				coverages[i] = testPackage(cr.Packages[i], pkgLog)
+				pkgLog.flush()
+			}
+		}()
+	}
+
+	for i := range cr.Packages {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Merge the profiles of all packages into combined reports
+	merged, err := MergedProfiles(coverages)
+	if err != nil {
+		log.Errorf("Error merging coverage profiles: %s", err.Error())
+		return coverages
 	}
+	if len(cr.CoverPkg) > 0 {
+		cr.attributeCoverage(coverages, merged)
+	}
+	cr.Overall = profileTotals(merged)
+	log.Infof("Overall coverage: %.2f%% (%d/%d statements)", cr.Overall.Percentage(), cr.Overall.Covered, cr.Overall.Statements)
+
+	if err := cr.writeCombinedReports(merged); err != nil {
+		log.Errorf("Error writing combined coverage reports: %s", err.Error())
+	}
+
 	return coverages
 }
 
-// testSinglePackage tests a single package and returns its coverage information
-func (cr *CoverageReporter) testSinglePackage(pkg finder.Package) Coverage {
-	log.Infof("Testing package: %s", pkg.Name)
+// PackageThreshold returns the coverage threshold of the named package,
+// falling back to the default threshold for unknown packages
+func (cr *CoverageReporter) PackageThreshold(packageName string) float64 {
+	if pkg, ok := cr.Package(packageName); ok {
+		if cr.UntestedPolicy == UntestedThreshold && isUntestedPackage(pkg) {
+			return cr.UntestedThreshold
+		}
+		return pkg.Threshold
+	}
+	return cr.DefaultCoverageThreshold
+}
+
+// Package returns the named package and whether it is known to the reporter
+func (cr *CoverageReporter) Package(packageName string) (finder.Package, bool) {
+	for _, pkg := range cr.Packages {
+		if pkg.Name == packageName {
+			return pkg, true
+		}
+	}
+	return finder.Package{}, false
+}
+
+// Passed reports whether the package tests succeeded and its coverage meets its threshold.
+// Packages without test files are judged by the untested packages policy unless
+// the tests of other packages cover them through coverpkg, and test-only
+// packages, which have no statements of their own, only by their tests.
+func (cr *CoverageReporter) Passed(cov Coverage) bool {
+	if cov.Untested() {
+		if len(cr.CoverPkg) > 0 && cov.Statements > 0 {
+			return cov.Percentage >= cr.PackageThreshold(cov.PackageName)
+		}
+		return cr.untestedPassed(cov)
+	}
+	if pkg, ok := cr.Package(cov.PackageName); ok && !pkg.HasGoFiles() {
+		return !cov.Status.Failed()
+	}
+	return !cov.Status.Failed() && cov.Percentage >= cr.PackageThreshold(cov.PackageName)
+}
+
+// PatchPassed reports whether the patch coverage meets the patch threshold
+func (cr *CoverageReporter) PatchPassed(patch PatchCoverage) bool {
+	return patch.Percentage() >= cr.PatchThreshold
+}
+
+// ReportFile returns the per-package coverage report that is left in the
+// reports directory once the run completes, or "" when it is removed: all
+// reports are removed unless they are kept, and the per-package profiles
+// are removed in favour of the combined Cobertura report.
+func (cr *CoverageReporter) ReportFile(cov Coverage) string {
+	if !cr.KeepReports || cr.OutputFormat == FormatCobertura {
+		return ""
+	}
+	return cov.CoverageFile
+}
+
+// testSinglePackage tests a single package and returns its coverage information.
+// Log messages are buffered in pkgLog so they can be flushed together.
+func (cr *CoverageReporter) testSinglePackage(pkg finder.Package, pkgLog *packageLog) Coverage {
+	if isUntestedPackage(pkg) {
+		pkgLog.Infof("Testing package without test files: %s", pkg.Name)
+	} else {
+		pkgLog.Infof("Testing package: %s", pkg.Name)
+	}
 
 	coverProfileName := filepath.Join(cr.ReportsDir, fmt.Sprintf("coverage_%s.out", strings.ReplaceAll(pkg.Name, "/", "_")))
-	cmd := exec.Command("go", "test", "-coverprofile="+coverProfileName, pkg.Name)
+	// A profile left by a previous run must not pass for this one when the
+	// package fails to build
+	if err := os.Remove(coverProfileName); err != nil && !errors.Is(err, os.ErrNotExist) {
+		pkgLog.Warnf("Failed to remove stale coverage profile %s: %s", coverProfileName, err.Error())
+	}
+	args := []string{"test", "-coverprofile=" + coverProfileName}
+	if coverPkg := cr.coverPkgFlag(pkg.Name); coverPkg != "" {
+		args = append(args, coverPkg)
+	}
+	if cr.RecordTests {
+		args = append(args, "-json")
+	}
+	cmd := exec.Command("go", append(args, pkg.Name)...)
 	output, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Errorf("Error testing package %s: %s", pkg.Name, err.Error())
-		return Coverage{PackageName: pkg.Name, Percentage: 0}
+	var tests []TestResult
+	if cr.RecordTests {
+		output, tests = parseTestEvents(output)
+	}
+	status := classifyTestOutput(output, err == nil)
+	if status == StatusPassed && isUntestedPackage(pkg) {
+		status = StatusNoTestFiles
+	}
+	result := Coverage{PackageName: pkg.Name, Status: status, Output: string(output), Tests: tests}
+	if status.Failed() {
+		pkgLog.Errorf("Error testing package %s (%s):\n%s", pkg.Name, status, output)
+		// go test still writes the profile when tests fail, so the statements
+		// of the package count towards the overall coverage
+		if _, err := os.Stat(coverProfileName); err == nil {
+			result.ProfileFile = coverProfileName
+			cr.applyPackageProfile(&result, pkgLog)
+		}
+		return result
+	}
+	if status == StatusNoTestFiles {
+		pkgLog.Warnf("Package %s has no test files", pkg.Name)
 	}
 
+	// The percentage printed by go test is a fallback for unreadable profiles
 	coverage, err := extractCoveragePercentage(output)
 	if err != nil {
-		log.Warnf("Failed to extract coverage percentage for package %s error: %s", pkg.Name, err)
-		return Coverage{PackageName: pkg.Name, Percentage: 0}
+		pkgLog.Warnf("Failed to extract coverage percentage for package %s error: %s", pkg.Name, err)
+		return result
 	}
+	result.Percentage = coverage
+	result.CoverageFile = coverProfileName
+	result.ProfileFile = coverProfileName
+
+	cr.applyPackageProfile(&result, pkgLog)
 
-	if cr.OutputFormat == "lcov" {
+	if cr.OutputFormat == FormatLcov {
 		lcovFile := strings.Replace(coverProfileName, ".out", ".lcov", 1)
-		err = convertToLcov(coverProfileName, lcovFile)
+		err = cr.convertToLcov(coverProfileName, lcovFile)
 		if err != nil {
-			log.Errorf("Error converting coverage profile to lcov for package %s: %s", pkg.Name, err.Error())
-			return Coverage{PackageName: pkg.Name, Percentage: coverage, CoverageFile: coverProfileName}
+			pkgLog.Errorf("Error converting coverage profile to lcov for package %s: %s", pkg.Name, err.Error())
+			return result
 		}
-		return Coverage{PackageName: pkg.Name, Percentage: coverage, CoverageFile: lcovFile}
+		result.CoverageFile = lcovFile
 	}
 
-	return Coverage{PackageName: pkg.Name, Percentage: coverage, CoverageFile: coverProfileName}
+	return result
 }
 
-// convertToLcov converts a Go coverage profile to lcov format using gcov2lcov
-func convertToLcov(inputFile, outputFile string) error {
-	cmd := exec.Command("gcov2lcov", "-infile", inputFile, "-outfile", outputFile)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to convert to lcov: %w", err)
+// applyPackageProfile applies the profile written by the tests of the package.
+// With -coverpkg the profile spans several packages; statements are
+// attributed to their packages once all packages have been tested.
+func (cr *CoverageReporter) applyPackageProfile(cov *Coverage, pkgLog *packageLog) {
+	if len(cr.CoverPkg) > 0 {
+		return
+	}
+	profiles, err := ParseProfileFile(cov.ProfileFile)
+	if err != nil {
+		pkgLog.Warnf("Failed to parse coverage profile for package %s: %s", cov.PackageName, err.Error())
+		return
+	}
+	cr.applyProfiles(cov, profiles)
+}
+
+// applyProfiles fills the coverage percentage, statement counts, file breakdown
+// and patch coverage of a package from the profiles of its source files. The
+// percentage replaces the rounded one printed by go test.
+func (cr *CoverageReporter) applyProfiles(cov *Coverage, profiles []*Profile) {
+	cov.Files = fileCoverages(profiles, cr.Paths)
+	cov.Statements, cov.CoveredStatements = 0, 0
+	for _, file := range cov.Files {
+		cov.Statements += file.Statements
+		cov.CoveredStatements += file.Covered
+	}
+	cov.Percentage = percentage(cov.CoveredStatements, cov.Statements)
+	if cr.changedLines != nil {
+		cov.Patch = patchCoverage(profiles, cr.changedLines, cr.Paths)
 	}
-	return nil
 }
 
 // extractCoveragePercentage extracts the coverage percentage from the command output
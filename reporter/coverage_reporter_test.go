@@ -0,0 +1,60 @@
+package reporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportFile(t *testing.T) {
+	cov := Coverage{PackageName: "example.com/mod/pkg", CoverageFile: "reports/coverage_example.com_mod_pkg.lcov"}
+
+	cr := &CoverageReporter{OutputFormat: FormatLcov, KeepReports: true}
+	assert.Equal(t, cov.CoverageFile, cr.ReportFile(cov))
+
+	cr.KeepReports = false
+	assert.Empty(t, cr.ReportFile(cov), "reports are removed after printing")
+
+	cr = &CoverageReporter{OutputFormat: FormatCobertura, KeepReports: true}
+	assert.Empty(t, cr.ReportFile(Coverage{CoverageFile: "reports/coverage_example.com_mod_pkg.out"}), "per-package profiles are removed in cobertura mode")
+}
+
+func TestTestPackagesOrderAndParallelism(t *testing.T) {
+	var packages []finder.Package
+	for i := 0; i < 8; i++ {
+		packages = append(packages, finder.Package{Name: fmt.Sprintf("example.com/mod/p%d", i)})
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	cr := &CoverageReporter{Packages: packages, Parallelism: 3, ReportsDir: t.TempDir(), OutputFormat: FormatOut}
+	cr.testPackage = func(pkg finder.Package, _ *packageLog) Coverage {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		// Later packages finish first
+		index, _ := strconv.Atoi(strings.TrimPrefix(pkg.Name, "example.com/mod/p"))
+		time.Sleep(time.Duration(len(packages)-index) * 5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return Coverage{PackageName: pkg.Name, Status: StatusPassed}
+	}
+
+	coverages := cr.TestPackages()
+	require.Len(t, coverages, len(packages))
+	for i, cov := range coverages {
+		assert.Equal(t, packages[i].Name, cov.PackageName)
+	}
+	assert.Equal(t, 3, maxRunning)
+}
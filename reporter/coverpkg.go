@@ -0,0 +1,37 @@
+package reporter
+
+import (
+	"path"
+	"strings"
+)
+
+// coverPkgFlag returns the -coverpkg flag for testing pkg, or an empty string
+// when cross-package coverage is disabled. The package itself is always
+// instrumented so its own tests keep contributing to its coverage.
+func (cr *CoverageReporter) coverPkgFlag(pkg string) string {
+	if len(cr.CoverPkg) == 0 {
+		return ""
+	}
+	pkgs := []string{pkg}
+	for _, p := range cr.CoverPkg {
+		if p != pkg {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return "-coverpkg=" + strings.Join(pkgs, ",")
+}
+
+// attributeCoverage assigns every statement of the merged profiles of all test
+// runs to the package that owns its source file, so code exercised by the
+// tests of other packages counts towards its own package
+func (cr *CoverageReporter) attributeCoverage(coverages []Coverage, merged []*Profile) {
+	owned := make(map[string][]*Profile)
+	for _, profile := range merged {
+		pkg := path.Dir(profile.FileName)
+		owned[pkg] = append(owned[pkg], profile)
+	}
+
+	for i := range coverages {
+		cr.applyProfiles(&coverages[i], owned[coverages[i].PackageName])
+	}
+}
@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverPkgFlag(t *testing.T) {
+	cr := &CoverageReporter{}
+	assert.Empty(t, cr.coverPkgFlag("example.com/cv/e2e"))
+
+	cr.CoverPkg = []string{"example.com/cv/lib", "example.com/cv/e2e"}
+	assert.Equal(t, "-coverpkg=example.com/cv/e2e,example.com/cv/lib", cr.coverPkgFlag("example.com/cv/e2e"))
+	assert.Equal(t, "-coverpkg=example.com/cv/api,example.com/cv/lib,example.com/cv/e2e", cr.coverPkgFlag("example.com/cv/api"))
+}
+
+func TestAttributeCoverage(t *testing.T) {
+	cr := &CoverageReporter{
+		Packages: []finder.Package{
+			{Name: "example.com/cv/lib", GoFiles: []string{"lib.go"}, Threshold: 50},
+			{Name: "example.com/cv/e2e", XTestGoFiles: []string{"e2e_test.go"}, Threshold: 50},
+			{Name: "example.com/cv/broken", GoFiles: []string{"broken.go"}, Threshold: 50},
+		},
+		CoverPkg:       []string{"example.com/cv/lib"},
+		UntestedPolicy: UntestedFail,
+	}
+	coverages := []Coverage{
+		{PackageName: "example.com/cv/lib", Status: StatusNoTestFiles},
+		{PackageName: "example.com/cv/e2e", Status: StatusPassed},
+		{PackageName: "example.com/cv/broken", Status: StatusTestFailed},
+	}
+	merged := []*Profile{
+		{FileName: "example.com/cv/lib/lib.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 1},
+			{StartLine: 7, StartCol: 10, EndLine: 9, EndCol: 2, NumStmt: 1, Count: 0},
+		}},
+		{FileName: "example.com/cv/broken/broken.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 4, Count: 1},
+		}},
+	}
+
+	cr.attributeCoverage(coverages, merged)
+
+	assert.Equal(t, 3, coverages[0].Statements)
+	assert.Equal(t, 2, coverages[0].CoveredStatements)
+	assert.InDelta(t, 66.67, coverages[0].Percentage, 0.01)
+	assert.True(t, cr.Passed(coverages[0]), "attributed coverage overrides the untested policy")
+	assert.Zero(t, coverages[1].Statements)
+	assert.True(t, cr.Passed(coverages[1]), "test-only packages pass when their tests pass")
+	assert.Equal(t, 4, coverages[2].Statements, "packages whose tests failed keep their statements")
+	assert.False(t, cr.Passed(coverages[2]))
+}
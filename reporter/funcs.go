@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// FuncExtent describes the position of a function declaration in a source file
+type FuncExtent struct {
+	Name      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+}
+
+// FindFuncs parses the Go source file and returns the extents of its functions and methods
+func FindFuncs(fileName string) ([]FuncExtent, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source file: %w", err)
+	}
+
+	var funcs []FuncExtent
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start := fset.Position(fn.Pos())
+		end := fset.Position(fn.End())
+		funcs = append(funcs, FuncExtent{
+			Name:      funcName(fn),
+			StartLine: start.Line,
+			StartCol:  start.Column,
+			EndLine:   end.Line,
+			EndCol:    end.Column,
+		})
+	}
+	return funcs, nil
+}
+
+// funcName returns the name of a function, qualified by its receiver type for methods
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	typ := fn.Recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+			continue
+		case *ast.IndexExpr:
+			typ = t.X
+			continue
+		case *ast.IndexListExpr:
+			typ = t.X
+			continue
+		case *ast.Ident:
+			return t.Name + "." + fn.Name.Name
+		}
+		return fn.Name.Name
+	}
+}
+
+// contains reports whether the block lies within the function extent
+func (f FuncExtent) contains(b ProfileBlock) bool {
+	startsAfter := b.StartLine > f.StartLine || (b.StartLine == f.StartLine && b.StartCol >= f.StartCol)
+	endsBefore := b.EndLine < f.EndLine || (b.EndLine == f.EndLine && b.EndCol <= f.EndCol)
+	return startsAfter && endsBefore
+}
@@ -0,0 +1,102 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryFileName is the JSON-lines history of coverage runs in the reports directory
+const HistoryFileName = "history.jsonl"
+
+// HistoryEntry records the coverage of a single run
+type HistoryEntry struct {
+	Timestamp time.Time          `json:"timestamp"`
+	GitCommit string             `json:"git_commit,omitempty"`
+	Total     float64            `json:"total"`
+	Packages  map[string]float64 `json:"packages"`
+}
+
+// HistoryFile returns the path of the history file in the reports directory
+func (cr *CoverageReporter) HistoryFile() string {
+	return filepath.Join(cr.ReportsDir, HistoryFileName)
+}
+
+// LoadHistory reads the entries of a history file, oldest first. A missing
+// file yields an error wrapping os.ErrNotExist.
+func LoadHistory(fileName string) ([]HistoryEntry, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse history %s line %d: %w", fileName, lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history %s: %w", fileName, err)
+	}
+	return entries, nil
+}
+
+// RecordHistory appends the coverage of this run to the history file.
+// Packages whose tests failed are left out.
+func (cr *CoverageReporter) RecordHistory(coverages []Coverage) error {
+	metadata := cr.Metadata()
+	entry := HistoryEntry{
+		Timestamp: metadata.Timestamp.UTC(),
+		GitCommit: metadata.GitCommit,
+		Total:     roundPercentage(cr.Overall.Percentage()),
+		Packages:  make(map[string]float64),
+	}
+	for _, cov := range coverages {
+		if !cov.Status.Failed() {
+			entry.Packages[cov.PackageName] = roundPercentage(cov.Percentage)
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(cr.HistoryFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// PreviousDelta returns the change in coverage of the package since the
+// previous run in the history, and whether the package was recorded then
+func (cr *CoverageReporter) PreviousDelta(cov Coverage) (float64, bool) {
+	if cr.Previous == nil {
+		return 0, false
+	}
+	previous, ok := cr.Previous.Packages[cov.PackageName]
+	if !ok {
+		return 0, false
+	}
+	return roundPercentage(cov.Percentage) - previous, true
+}
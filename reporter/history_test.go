@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	cr := &CoverageReporter{ReportsDir: t.TempDir(), Overall: Totals{Statements: 3, Covered: 1}}
+	_, err := LoadHistory(cr.HistoryFile())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, cr.RecordHistory([]Coverage{
+		{PackageName: "example.com/mod/a", Percentage: 50, Status: StatusPassed},
+		{PackageName: "example.com/mod/b", Percentage: 10, Status: StatusBuildFailed},
+	}))
+	require.NoError(t, cr.RecordHistory([]Coverage{
+		{PackageName: "example.com/mod/a", Percentage: 60, Status: StatusPassed},
+	}))
+
+	entries, err := LoadHistory(cr.HistoryFile())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 33.33, entries[0].Total)
+	assert.Equal(t, map[string]float64{"example.com/mod/a": 50}, entries[0].Packages)
+
+	cr.Previous = &entries[0]
+	delta, ok := cr.PreviousDelta(Coverage{PackageName: "example.com/mod/a", Percentage: 60})
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, delta)
+	_, ok = cr.PreviousDelta(Coverage{PackageName: "example.com/mod/b"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,104 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// convertToLcov converts a Go coverage profile to lcov format
+func (cr *CoverageReporter) convertToLcov(inputFile, outputFile string) error {
+	profiles, err := ParseProfileFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to convert to lcov: %w", err)
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create lcov file: %w", err)
+	}
+	defer f.Close()
+
+	if err := WriteLcov(f, profiles, cr.Paths); err != nil {
+		return fmt.Errorf("failed to convert to lcov: %w", err)
+	}
+	return f.Close()
+}
+
+// WriteLcov writes the profiles as LCOV tracefile records. Source files are
+// resolved with the given resolver to emit repository-relative paths and
+// function records; functions are omitted for sources that cannot be read.
+func WriteLcov(w io.Writer, profiles []*Profile, paths *PathResolver) error {
+	bw := bufio.NewWriter(w)
+
+	for _, profile := range profiles {
+		fmt.Fprintln(bw, "TN:")
+		fmt.Fprintf(bw, "SF:%s\n", paths.RelPath(profile.FileName))
+
+		funcs, err := FindFuncs(paths.AbsPath(profile.FileName))
+		if err == nil {
+			writeLcovFuncs(bw, profile, funcs)
+		}
+
+		lines := LineCounts(profile)
+		lineNumbers := make([]int, 0, len(lines))
+		for line := range lines {
+			lineNumbers = append(lineNumbers, line)
+		}
+		sort.Ints(lineNumbers)
+
+		hit := 0
+		for _, line := range lineNumbers {
+			fmt.Fprintf(bw, "DA:%d,%d\n", line, lines[line])
+			if lines[line] > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(bw, "LF:%d\n", len(lineNumbers))
+		fmt.Fprintf(bw, "LH:%d\n", hit)
+		fmt.Fprintln(bw, "end_of_record")
+	}
+
+	return bw.Flush()
+}
+
+// writeLcovFuncs writes the FN, FNDA, FNF and FNH records of a file. A function
+// is considered executed as many times as its first block.
+func writeLcovFuncs(w io.Writer, profile *Profile, funcs []FuncExtent) {
+	counts := make([]int, len(funcs))
+	for i, fn := range funcs {
+		fmt.Fprintf(w, "FN:%d,%s\n", fn.StartLine, fn.Name)
+		for _, b := range profile.Blocks {
+			if fn.contains(b) {
+				counts[i] = b.Count
+				break
+			}
+		}
+	}
+
+	hit := 0
+	for i, fn := range funcs {
+		fmt.Fprintf(w, "FNDA:%d,%s\n", counts[i], fn.Name)
+		if counts[i] > 0 {
+			hit++
+		}
+	}
+	fmt.Fprintf(w, "FNF:%d\n", len(funcs))
+	fmt.Fprintf(w, "FNH:%d\n", hit)
+}
+
+// LineCounts returns the execution count of every line covered by a block.
+// Lines shared by several blocks get the highest count.
+func LineCounts(profile *Profile) map[int]int {
+	lines := make(map[int]int)
+	for _, b := range profile.Blocks {
+		for line := b.StartLine; line <= b.EndLine; line++ {
+			if count, ok := lines[line]; !ok || b.Count > count {
+				lines[line] = b.Count
+			}
+		}
+	}
+	return lines
+}
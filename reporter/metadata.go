@@ -0,0 +1,41 @@
+package reporter
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// RunMetadata describes the environment of a coverage run
+type RunMetadata struct {
+	Module    string
+	GoVersion string
+	Timestamp time.Time
+	GitCommit string
+}
+
+// Metadata returns the metadata of the current coverage run. Values that
+// cannot be determined are left empty.
+func (cr *CoverageReporter) Metadata() RunMetadata {
+	metadata := RunMetadata{
+		GoVersion: commandOutput("go", "env", "GOVERSION"),
+		Timestamp: cr.StartedAt,
+		GitCommit: commandOutput("git", "rev-parse", "HEAD"),
+	}
+	if cr.Paths != nil {
+		metadata.Module = cr.Paths.ModulePath
+	}
+	if metadata.Timestamp.IsZero() {
+		metadata.Timestamp = time.Now()
+	}
+	return metadata
+}
+
+// commandOutput runs the command and returns its trimmed output, or an empty string on failure
+func commandOutput(name string, args ...string) string {
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// flushMu serializes flushing of package logs so that the output of one
+// package is never interleaved with the output of another
+var flushMu sync.Mutex
+
+// logEntry is a single buffered log message
+type logEntry struct {
+	level log.Level
+	msg   string
+}
+
+// packageLog buffers the log messages of a single package test run
+type packageLog struct {
+	entries []logEntry
+}
+
+func (pl *packageLog) logf(level log.Level, format string, args ...interface{}) {
+	pl.entries = append(pl.entries, logEntry{level: level, msg: fmt.Sprintf(format, args...)})
+}
+
+// Debugf buffers a debug message
+func (pl *packageLog) Debugf(format string, args ...interface{}) {
+	pl.logf(log.DebugLevel, format, args...)
+}
+
+// Infof buffers an info message
+func (pl *packageLog) Infof(format string, args ...interface{}) {
+	pl.logf(log.InfoLevel, format, args...)
+}
+
+// Warnf buffers a warning message
+func (pl *packageLog) Warnf(format string, args ...interface{}) {
+	pl.logf(log.WarnLevel, format, args...)
+}
+
+// Errorf buffers an error message
+func (pl *packageLog) Errorf(format string, args ...interface{}) {
+	pl.logf(log.ErrorLevel, format, args...)
+}
+
+// flush writes all buffered messages to the global logger in one block
+func (pl *packageLog) flush() {
+	flushMu.Lock()
+	defer flushMu.Unlock()
+
+	for _, entry := range pl.entries {
+		log.Log(entry.level, entry.msg)
+	}
+	pl.entries = nil
+}
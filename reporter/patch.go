@@ -0,0 +1,193 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -[0-9]+(?:,[0-9]+)? \+([0-9]+)(?:,([0-9]+))? @@`)
+
+// ChangedLines maps repository-relative file paths to the set of changed line numbers
+type ChangedLines map[string]map[int]bool
+
+// PatchCoverage holds the coverage of the changed lines of a package. Only
+// changed lines that contain statements are counted.
+type PatchCoverage struct {
+	Lines     int
+	Covered   int
+	Uncovered []FileLine
+}
+
+// FileLine identifies a line in a repository-relative file
+type FileLine struct {
+	FileName string
+	Line     int
+}
+
+// Percentage returns the percentage of covered changed lines. A patch without
+// coverable lines is fully covered.
+func (pc PatchCoverage) Percentage() float64 {
+	if pc.Lines == 0 {
+		return 100
+	}
+	return percentage(pc.Covered, pc.Lines)
+}
+
+// OverallPatchCoverage sums the patch coverage of all packages
+func OverallPatchCoverage(coverages []Coverage) PatchCoverage {
+	var overall PatchCoverage
+	for _, cov := range coverages {
+		if cov.Patch == nil {
+			continue
+		}
+		overall.Lines += cov.Patch.Lines
+		overall.Covered += cov.Patch.Covered
+		overall.Uncovered = append(overall.Uncovered, cov.Patch.Uncovered...)
+	}
+	return overall
+}
+
+// GitChangedLines returns the lines changed between the merge base of baseRef
+// and HEAD and the working tree of the repository at rootDir. All lines of
+// untracked files count as changed.
+func GitChangedLines(rootDir, baseRef string) (ChangedLines, error) {
+	cmd := exec.Command("git", "merge-base", baseRef, "HEAD")
+	cmd.Dir = rootDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error finding merge base with %s: %w", baseRef, err)
+	}
+	mergeBase := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "diff", "--unified=0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", mergeBase)
+	cmd.Dir = rootDir
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git diff against %s: %w", baseRef, err)
+	}
+	changed, err := ParseUnifiedDiff(bytes.NewReader(output))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	cmd.Dir = rootDir
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing untracked files: %w", err)
+	}
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Only Go files have coverage
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(rootDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("error reading untracked file %s: %w", name, err)
+		}
+		changed[name] = allLines(content)
+	}
+	return changed, nil
+}
+
+// allLines returns the set of all line numbers of the content
+func allLines(content []byte) map[int]bool {
+	lines := make(map[int]bool)
+	count := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		count++
+	}
+	for line := 1; line <= count; line++ {
+		lines[line] = true
+	}
+	return lines
+}
+
+// ParseUnifiedDiff extracts the added and modified lines of every file in a unified diff
+func ParseUnifiedDiff(r io.Reader) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	var current map[int]bool
+	// File headers only appear between a "diff --git" line and the first
+	// hunk, so added lines starting with "++ " are not mistaken for them
+	inHeader := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader, current = true, nil
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				current = nil
+				continue
+			}
+			name = strings.TrimPrefix(name, "b/")
+			current = make(map[int]bool)
+			changed[name] = current
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			if current == nil {
+				continue
+			}
+			match := hunkHeaderRegex.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, _ := strconv.Atoi(match[1])
+			count := 1
+			if match[2] != "" {
+				count, _ = strconv.Atoi(match[2])
+			}
+			for l := start; l < start+count; l++ {
+				current[l] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// patchCoverage intersects the changed lines with the coverable lines of the profiles
+func patchCoverage(profiles []*Profile, changed ChangedLines, paths *PathResolver) *PatchCoverage {
+	patch := &PatchCoverage{}
+	for _, profile := range profiles {
+		fileName := paths.RelPath(profile.FileName)
+		changedInFile := changed[fileName]
+		if len(changedInFile) == 0 {
+			continue
+		}
+
+		lines := LineCounts(profile)
+		numbers := make([]int, 0, len(lines))
+		for number := range lines {
+			if changedInFile[number] {
+				numbers = append(numbers, number)
+			}
+		}
+		sort.Ints(numbers)
+
+		for _, number := range numbers {
+			patch.Lines++
+			if lines[number] > 0 {
+				patch.Covered++
+			} else {
+				patch.Uncovered = append(patch.Uncovered, FileLine{FileName: fileName, Line: number})
+			}
+		}
+	}
+	return patch
+}
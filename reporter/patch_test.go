@@ -0,0 +1,83 @@
+package reporter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -3,0 +4,2 @@ func a() {
++	x := 1
++	y := 2
+@@ -10 +12 @@ func b() {
+-	return 0
++	return 1
+@@ -20,3 +22,0 @@ func c() {
+@@ -30,0 +31,1 @@ func d() {
++++ counter
+diff --git a/pkg/old.go b/pkg/old.go
+deleted file mode 100644
+--- a/pkg/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+`
+	changed, err := ParseUnifiedDiff(strings.NewReader(diff))
+	require.NoError(t, err)
+	assert.Equal(t, ChangedLines{"pkg/a.go": {4: true, 5: true, 12: true, 31: true}}, changed)
+}
+
+func TestPatchCoverage(t *testing.T) {
+	profiles := []*Profile{{
+		FileName: "example.com/mod/pkg/a.go",
+		Mode:     ModeSet,
+		Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 1},
+			{StartLine: 11, StartCol: 2, EndLine: 13, EndCol: 3, NumStmt: 2, Count: 0},
+		},
+	}}
+	paths := &PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src", RootDir: "/src"}
+	changed := ChangedLines{"pkg/a.go": {4: true, 5: true, 8: true, 12: true}}
+
+	patch := patchCoverage(profiles, changed, paths)
+	assert.Equal(t, 3, patch.Lines)
+	assert.Equal(t, 2, patch.Covered)
+	assert.Equal(t, []FileLine{{FileName: "pkg/a.go", Line: 12}}, patch.Uncovered)
+}
+
+func TestGitChangedLines(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	git("init", "-q")
+	// The diff prefixes of the user's configuration must not leak into file names
+	git("config", "diff.mnemonicPrefix", "true")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "old.go"), []byte("package pkg\n\nvar x = 1\n"), 0644))
+	git("add", "pkg/old.go")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "old.go"), []byte("package pkg\n\nvar x = 2\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "new.go"), []byte("package pkg\n\nfunc f() {}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not go\n"), 0644))
+
+	changed, err := GitChangedLines(dir, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, ChangedLines{
+		"pkg/old.go": {3: true},
+		"pkg/new.go": {1: true, 2: true, 3: true},
+	}, changed)
+}
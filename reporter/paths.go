@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PathResolver maps the import-path based file names of coverage profiles
+// to files on disk
+type PathResolver struct {
+	ModulePath string
+	ModuleDir  string
+	RootDir    string
+}
+
+// NewPathResolver creates a PathResolver for the module containing dir.
+// File paths are made relative to the enclosing git repository, or to the
+// module directory when dir is not inside a repository.
+func NewPathResolver(dir string) (*PathResolver, error) {
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving module of %s: %w", dir, err)
+	}
+	goMod := strings.TrimSpace(string(output))
+	if goMod == "" || goMod == os.DevNull {
+		return nil, fmt.Errorf("%s is not inside a Go module", dir)
+	}
+
+	// Within a workspace go list -m lists every workspace module, so the
+	// workspace is disabled to describe only the module of dir
+	cmd = exec.Command("go", "list", "-m", "-json")
+	cmd.Dir = filepath.Dir(goMod)
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving module of %s: %w", dir, err)
+	}
+
+	var module struct {
+		Path string
+		Dir  string
+	}
+	if err := json.Unmarshal(output, &module); err != nil {
+		return nil, fmt.Errorf("error decoding go list output: %w", err)
+	}
+
+	resolver := &PathResolver{
+		ModulePath: module.Path,
+		ModuleDir:  module.Dir,
+		RootDir:    module.Dir,
+	}
+
+	cmd = exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = resolver.ModuleDir
+	if output, err := cmd.Output(); err == nil {
+		resolver.RootDir = strings.TrimSpace(string(output))
+	}
+
+	return resolver, nil
+}
+
+// AbsPath returns the absolute path on disk of a profile file name
+func (r *PathResolver) AbsPath(fileName string) string {
+	if r == nil || r.ModulePath == "" {
+		return fileName
+	}
+	if rest, ok := strings.CutPrefix(fileName, r.ModulePath+"/"); ok {
+		return filepath.Join(r.ModuleDir, filepath.FromSlash(rest))
+	}
+	return fileName
+}
+
+// RelPath returns the repository-relative, slash-separated path of a profile file name
+func (r *PathResolver) RelPath(fileName string) string {
+	abs := r.AbsPath(fileName)
+	if r == nil || abs == fileName {
+		return fileName
+	}
+	rel, err := filepath.Rel(r.RootDir, abs)
+	if err != nil {
+		return fileName
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,37 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPathResolverInWorkspace(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	files := map[string]string{
+		"go.work":          "go 1.22\n\nuse (\n\t./first\n\t./second\n)\n",
+		"first/go.mod":     "module example.com/first\n\ngo 1.22\n",
+		"second/go.mod":    "module example.com/second\n\ngo 1.22\n",
+		"second/pkg/a.go":  "package pkg\n",
+		"outside/notes.md": "not a module\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	resolver, err := NewPathResolver(filepath.Join(dir, "second", "pkg"))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/second", resolver.ModulePath)
+	assert.Equal(t, filepath.Join(dir, "second"), resolver.ModuleDir)
+	assert.Equal(t, filepath.Join(dir, "second", "pkg", "a.go"), resolver.AbsPath("example.com/second/pkg/a.go"))
+
+	t.Setenv("GOWORK", "off")
+	_, err = NewPathResolver(filepath.Join(dir, "outside"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported cover modes of a Go coverage profile
+const (
+	ModeSet    = "set"
+	ModeCount  = "count"
+	ModeAtomic = "atomic"
+)
+
+var profileLineRegex = regexp.MustCompile(`^(.+):([0-9]+)\.([0-9]+),([0-9]+)\.([0-9]+) ([0-9]+) ([0-9]+)$`)
+
+// ProfileBlock represents a single block of a Go coverage profile
+type ProfileBlock struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// Profile represents the coverage blocks of a single source file
+type Profile struct {
+	FileName string
+	Mode     string
+	Blocks   []ProfileBlock
+}
+
+// ParseProfileFile parses the Go coverage profile stored in the given file
+func ParseProfileFile(fileName string) ([]*Profile, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open coverage profile: %w", err)
+	}
+	defer f.Close()
+
+	profiles, err := ParseProfiles(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse coverage profile %s: %w", fileName, err)
+	}
+	return profiles, nil
+}
+
+// ParseProfiles parses a Go coverage profile in set, count or atomic mode.
+// The returned profiles are sorted by file name and their blocks by position,
+// with duplicate blocks merged.
+func ParseProfiles(r io.Reader) ([]*Profile, error) {
+	files := make(map[string]*Profile)
+	mode := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if mode == "" {
+			if !strings.HasPrefix(line, "mode: ") {
+				return nil, fmt.Errorf("line %d: missing mode line", lineNumber)
+			}
+			mode = strings.TrimPrefix(line, "mode: ")
+			if mode != ModeSet && mode != ModeCount && mode != ModeAtomic {
+				return nil, fmt.Errorf("line %d: unsupported cover mode %q", lineNumber, mode)
+			}
+			continue
+		}
+
+		// Concatenated profiles repeat the mode line
+		if strings.HasPrefix(line, "mode: ") {
+			continue
+		}
+
+		match := profileLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: malformed profile line %q", lineNumber, line)
+		}
+
+		profile, ok := files[match[1]]
+		if !ok {
+			profile = &Profile{FileName: match[1], Mode: mode}
+			files[match[1]] = profile
+		}
+		profile.Blocks = append(profile.Blocks, ProfileBlock{
+			StartLine: atoi(match[2]),
+			StartCol:  atoi(match[3]),
+			EndLine:   atoi(match[4]),
+			EndCol:    atoi(match[5]),
+			NumStmt:   atoi(match[6]),
+			Count:     atoi(match[7]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*Profile, 0, len(files))
+	for _, profile := range files {
+		profile.Blocks = mergeBlocks(profile.Mode, profile.Blocks)
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].FileName < profiles[j].FileName
+	})
+	return profiles, nil
+}
+
+// MergeProfiles combines the profiles of the same source files into one
+// profile per file, sorted by file name. Profiles recorded in different cover
+// modes are merged in the least precise mode: set if any profile is in set
+// mode, atomic when count and atomic profiles are mixed. Blocks that overlap
+// an earlier block, such as the same block recorded by several test runs,
+// are merged into it so their statements are only counted once.
+func MergeProfiles(profiles []*Profile) []*Profile {
+	mode := ""
+	for _, profile := range profiles {
+		mode = mergeModes(mode, profile.Mode)
+	}
+
+	files := make(map[string]*Profile)
+	var names []string
+	for _, profile := range profiles {
+		merged, ok := files[profile.FileName]
+		if !ok {
+			merged = &Profile{FileName: profile.FileName, Mode: mode}
+			files[profile.FileName] = merged
+			names = append(names, profile.FileName)
+		}
+		merged.Blocks = append(merged.Blocks, profile.Blocks...)
+	}
+
+	sort.Strings(names)
+	result := make([]*Profile, 0, len(names))
+	for _, name := range names {
+		merged := files[name]
+		if mode == ModeSet {
+			for i := range merged.Blocks {
+				merged.Blocks[i].Count = min(merged.Blocks[i].Count, 1)
+			}
+		}
+		merged.Blocks = mergeBlocks(merged.Mode, merged.Blocks)
+		result = append(result, merged)
+	}
+	return result
+}
+
+// mergeModes returns the cover mode able to represent profiles of both modes
+func mergeModes(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case a == ModeSet || b == ModeSet:
+		return ModeSet
+	}
+	return ModeAtomic
+}
+
+// WriteProfile writes the profiles in the Go cover profile format. All
+// profiles are expected to share the same mode.
+func WriteProfile(w io.Writer, profiles []*Profile) error {
+	mode := ModeSet
+	if len(profiles) > 0 {
+		mode = profiles[0].Mode
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", mode)
+	for _, profile := range profiles {
+		for _, b := range profile.Blocks {
+			fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n", profile.FileName, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		}
+	}
+	return bw.Flush()
+}
+
+// mergeBlocks sorts blocks by position and merges blocks overlapping the
+// preceding block into it, keeping the range and statements of the first
+func mergeBlocks(mode string, blocks []ProfileBlock) []ProfileBlock {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		bi, bj := blocks[i], blocks[j]
+		if bi.StartLine != bj.StartLine {
+			return bi.StartLine < bj.StartLine
+		}
+		return bi.StartCol < bj.StartCol
+	})
+
+	merged := blocks[:0]
+	for _, b := range blocks {
+		if n := len(merged); n > 0 && merged[n-1].overlaps(b) {
+			last := &merged[n-1]
+			if mode == ModeSet {
+				last.Count |= b.Count
+			} else {
+				last.Count += b.Count
+			}
+			continue
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// overlaps reports whether block o, which starts at or after b, starts before b ends
+func (b ProfileBlock) overlaps(o ProfileBlock) bool {
+	return o.StartLine < b.EndLine || (o.StartLine == b.EndLine && o.StartCol < b.EndCol)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfiles(t *testing.T) {
+	input := `mode: count
+example.com/mod/b.go:3.10,5.2 2 1
+example.com/mod/a.go:7.2,8.3 1 0
+example.com/mod/a.go:3.10,5.2 2 4
+mode: count
+example.com/mod/a.go:3.10,5.2 2 1
+`
+	profiles, err := ParseProfiles(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+
+	assert.Equal(t, "example.com/mod/a.go", profiles[0].FileName)
+	assert.Equal(t, ModeCount, profiles[0].Mode)
+	assert.Equal(t, []ProfileBlock{
+		{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 5},
+		{StartLine: 7, StartCol: 2, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 0},
+	}, profiles[0].Blocks)
+	assert.Equal(t, "example.com/mod/b.go", profiles[1].FileName)
+}
+
+func TestParseProfilesErrors(t *testing.T) {
+	tests := []string{
+		"example.com/mod/a.go:3.10,5.2 2 1\n",
+		"mode: bogus\n",
+		"mode: set\nexample.com/mod/a.go:3.10 2 1\n",
+	}
+
+	for _, input := range tests {
+		_, err := ParseProfiles(strings.NewReader(input))
+		assert.Error(t, err, input)
+	}
+}
+
+func TestWriteLcov(t *testing.T) {
+	profiles := []*Profile{{
+		FileName: "example.com/mod/pkg/a.go",
+		Mode:     ModeSet,
+		Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 1},
+			{StartLine: 4, StartCol: 2, EndLine: 5, EndCol: 3, NumStmt: 1, Count: 0},
+		},
+	}}
+	paths := &PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src/mod", RootDir: "/src"}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteLcov(&buf, profiles, paths))
+
+	expected := `TN:
+SF:mod/pkg/a.go
+DA:3,1
+DA:4,1
+DA:5,0
+LF:3
+LH:2
+end_of_record
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestMergeProfiles(t *testing.T) {
+	profiles := []*Profile{
+		{FileName: "example.com/mod/a.go", Mode: ModeCount, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 3},
+			{StartLine: 7, StartCol: 2, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 0},
+		}},
+		{FileName: "example.com/mod/a.go", Mode: ModeSet, Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 1},
+			{StartLine: 7, StartCol: 4, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 1},
+		}},
+	}
+
+	merged := MergeProfiles(profiles)
+	require.Len(t, merged, 1)
+	assert.Equal(t, ModeSet, merged[0].Mode)
+	assert.Equal(t, []ProfileBlock{
+		{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, NumStmt: 2, Count: 1},
+		{StartLine: 7, StartCol: 2, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 1},
+	}, merged[0].Blocks)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteProfile(&buf, merged))
+	assert.Equal(t, "mode: set\nexample.com/mod/a.go:3.10,5.2 2 1\nexample.com/mod/a.go:7.2,8.3 1 1\n", buf.String())
+	assert.Equal(t, ModeAtomic, mergeModes(ModeCount, ModeAtomic))
+}
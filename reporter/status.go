@@ -0,0 +1,45 @@
+package reporter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Status describes the outcome of testing a package
+type Status string
+
+const (
+	StatusPassed      Status = "passed"
+	StatusTestFailed  Status = "test-failed"
+	StatusBuildFailed Status = "build-failed"
+	StatusNoTestFiles Status = "no-test-files"
+	StatusTimeout     Status = "timeout"
+)
+
+// noTestFilesRegex matches the go test summary of a package without test files.
+// Recent Go versions print a coverage line without the "ok" prefix instead of
+// "[no test files]" when a cover profile is requested. Both are anchored to
+// the summary line so that lines logged by tests in verbose output do not match.
+var noTestFilesRegex = regexp.MustCompile(`(?m)(^\?\s+\S+\s+\[no test files\]$|^\t\S+\t+coverage: )`)
+
+// Failed reports whether the status denotes a test, build or timeout failure
+func (s Status) Failed() bool {
+	return s == StatusTestFailed || s == StatusBuildFailed || s == StatusTimeout
+}
+
+// classifyTestOutput determines the status of a go test run from its output
+// and whether the command exited successfully
+func classifyTestOutput(output []byte, succeeded bool) Status {
+	out := string(output)
+	switch {
+	case strings.Contains(out, "panic: test timed out after"):
+		return StatusTimeout
+	case strings.Contains(out, "[build failed]"), strings.Contains(out, "[setup failed]"):
+		return StatusBuildFailed
+	case !succeeded:
+		return StatusTestFailed
+	case noTestFilesRegex.MatchString(out):
+		return StatusNoTestFiles
+	}
+	return StatusPassed
+}
@@ -0,0 +1,27 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyTestOutput(t *testing.T) {
+	tests := []struct {
+		output    string
+		succeeded bool
+		expected  Status
+	}{
+		{"ok  \texample.com/mod/pkg\t0.003s\tcoverage: 10.4% of statements\n", true, StatusPassed},
+		{"\texample.com/mod/pkg\t\tcoverage: 0.0% of statements\n", true, StatusNoTestFiles},
+		{"?   \texample.com/mod/pkg\t[no test files]\n", true, StatusNoTestFiles},
+		{"=== RUN   TestFoo\n    foo_test.go:9: \tcoverage: 12\n    foo_test.go:10: [no test files]\n--- PASS: TestFoo (0.00s)\nok  \texample.com/mod/pkg\t0.003s\tcoverage: 10.4% of statements\n", true, StatusPassed},
+		{"--- FAIL: TestFoo (0.00s)\nFAIL\texample.com/mod/pkg\t0.003s\n", false, StatusTestFailed},
+		{"pkg/a.go:3:1: syntax error\nFAIL\texample.com/mod/pkg [build failed]\n", false, StatusBuildFailed},
+		{"panic: test timed out after 10m0s\nFAIL\texample.com/mod/pkg\t600.1s\n", false, StatusTimeout},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, classifyTestOutput([]byte(tt.output), tt.succeeded), tt.output)
+	}
+}
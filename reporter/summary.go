@@ -0,0 +1,24 @@
+package reporter
+
+// Summary aggregates the coverage results of all packages
+type Summary struct {
+	Total        Totals
+	Passed       int
+	Failed       int
+	GlobalPassed bool
+}
+
+// Summarize computes the aggregate coverage from statement counts, counts the
+// passing and failing packages and checks the global threshold
+func (cr *CoverageReporter) Summarize(coverages []Coverage) Summary {
+	summary := Summary{Total: cr.Overall}
+	for _, cov := range coverages {
+		if cr.Passed(cov) {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+	summary.GlobalPassed = cr.GlobalThreshold == 0 || summary.Total.Percentage() >= cr.GlobalThreshold
+	return summary
+}
@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	packages := []finder.Package{
+		{Name: "example.com/mod/a", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/b", GoFiles: []string{"b.go"}, TestGoFiles: []string{"b_test.go"}, Threshold: 50},
+		{Name: "example.com/mod/c", GoFiles: []string{"c.go"}, TestGoFiles: []string{"c_test.go"}, Threshold: 50},
+	}
+	coverages := []Coverage{
+		{PackageName: "example.com/mod/a", Status: StatusPassed, Percentage: 80},
+		{PackageName: "example.com/mod/b", Status: StatusPassed, Percentage: 20},
+		{PackageName: "example.com/mod/c", Status: StatusTestFailed, Percentage: 90},
+	}
+	overall := Totals{Statements: 40, Covered: 26}
+
+	tests := []struct {
+		globalThreshold float64
+		globalPassed    bool
+	}{
+		{0, true},
+		{65, true},
+		{65.1, false},
+	}
+	for _, tt := range tests {
+		cr := &CoverageReporter{Packages: packages, Overall: overall, GlobalThreshold: tt.globalThreshold}
+		summary := cr.Summarize(coverages)
+		assert.Equal(t, Summary{Total: overall, Passed: 1, Failed: 2, GlobalPassed: tt.globalPassed}, summary, "global threshold %.1f", tt.globalThreshold)
+		assert.Equal(t, 65.0, summary.Total.Percentage())
+	}
+
+	assert.Equal(t, Summary{GlobalPassed: true}, (&CoverageReporter{}).Summarize(nil))
+}
@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Outcomes of a single test reported by go test -json
+const (
+	TestPass = "pass"
+	TestFail = "fail"
+	TestSkip = "skip"
+)
+
+// TestResult is the outcome of a single test or subtest of a package
+type TestResult struct {
+	Name    string
+	Outcome string
+	Elapsed float64 // seconds
+	Output  string
+}
+
+// testEvent is an event of the go test -json (test2json) stream
+type testEvent struct {
+	Action  string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parseTestEvents decodes go test -json output. It returns the plain text
+// output of the run, as go test would have printed it without -json, and
+// the results of the individual tests in the order they finished. Lines that
+// are not JSON events, such as build errors printed by older Go versions,
+// are kept in the text output. The output of a test keeps only the lines it
+// logged, without the "=== RUN" and "--- PASS" framing.
+func parseTestEvents(output []byte) ([]byte, []TestResult) {
+	var text bytes.Buffer
+	var results []TestResult
+	logs := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var event testEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &event) != nil {
+			text.Write(line)
+			text.WriteByte('\n')
+			continue
+		}
+		text.WriteString(event.Output)
+		if event.Test == "" {
+			continue
+		}
+
+		switch event.Action {
+		case "output":
+			if logged := strings.TrimSpace(event.Output); logged != "" && !isTestFraming(logged) {
+				logs[event.Test] += logged + "\n"
+			}
+		case TestPass, TestFail, TestSkip:
+			results = append(results, TestResult{
+				Name:    event.Test,
+				Outcome: event.Action,
+				Elapsed: event.Elapsed,
+				Output:  logs[event.Test],
+			})
+			delete(logs, event.Test)
+		}
+	}
+	return text.Bytes(), results
+}
+
+// isTestFraming reports whether a trimmed output line is one of the lines
+// go test prints around every test rather than a line the test logged
+func isTestFraming(line string) bool {
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- PASS", "--- FAIL", "--- SKIP"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/mod/pkg","Test":"TestA"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestA","Output":"--- PASS: TestA (0.01s)\n"}
+{"Action":"pass","Package":"example.com/mod/pkg","Test":"TestA","Elapsed":0.01}
+{"Action":"run","Package":"example.com/mod/pkg","Test":"TestB"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestB","Output":"=== RUN   TestB\n"}
+{"Action":"run","Package":"example.com/mod/pkg","Test":"TestB/sub"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestB/sub","Output":"=== RUN   TestB/sub\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestB/sub","Output":"    b_test.go:12: want 1, got 2\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestB/sub","Output":"    --- FAIL: TestB/sub (0.00s)\n"}
+{"Action":"fail","Package":"example.com/mod/pkg","Test":"TestB/sub","Elapsed":0}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestB","Output":"--- FAIL: TestB (0.00s)\n"}
+{"Action":"fail","Package":"example.com/mod/pkg","Test":"TestB","Elapsed":0}
+{"Action":"run","Package":"example.com/mod/pkg","Test":"TestC"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestC","Output":"=== RUN   TestC\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestC","Output":"    c_test.go:5: not on this platform\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Test":"TestC","Output":"--- SKIP: TestC (0.00s)\n"}
+{"Action":"skip","Package":"example.com/mod/pkg","Test":"TestC","Elapsed":0}
+{"Action":"output","Package":"example.com/mod/pkg","Output":"FAIL\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Output":"coverage: 42.0% of statements\n"}
+{"Action":"output","Package":"example.com/mod/pkg","Output":"FAIL\texample.com/mod/pkg\t0.015s\n"}
+{"Action":"fail","Package":"example.com/mod/pkg","Elapsed":0.015}
+`
+	text, results := parseTestEvents([]byte(output))
+	assert.Equal(t, []TestResult{
+		{Name: "TestA", Outcome: TestPass, Elapsed: 0.01},
+		{Name: "TestB/sub", Outcome: TestFail, Output: "b_test.go:12: want 1, got 2\n"},
+		{Name: "TestB", Outcome: TestFail},
+		{Name: "TestC", Outcome: TestSkip, Output: "c_test.go:5: not on this platform\n"},
+	}, results)
+	assert.Contains(t, string(text), "    b_test.go:12: want 1, got 2\n")
+	assert.Contains(t, string(text), "coverage: 42.0% of statements\nFAIL\texample.com/mod/pkg\t0.015s\n")
+}
+
+func TestParseTestEventsBuildFailure(t *testing.T) {
+	output := `# example.com/mod/pkg [example.com/mod/pkg.test]
+pkg/a.go:4:1: undefined: x
+{"Action":"output","Package":"example.com/mod/pkg","Output":"FAIL\texample.com/mod/pkg [build failed]\n"}
+{"Action":"fail","Package":"example.com/mod/pkg","Elapsed":0}
+`
+	text, results := parseTestEvents([]byte(output))
+	assert.Empty(t, results)
+	assert.Equal(t, "# example.com/mod/pkg [example.com/mod/pkg.test]\npkg/a.go:4:1: undefined: x\nFAIL\texample.com/mod/pkg [build failed]\n", string(text))
+	assert.Equal(t, StatusBuildFailed, classifyTestOutput(text, false))
+}
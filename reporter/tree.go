@@ -0,0 +1,166 @@
+package reporter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// TreeNode is a directory of the import path tree with the statement counts
+// of all packages beneath it
+type TreeNode struct {
+	// Path is the full import path prefix of the node
+	Path string
+	// Name is the last path element, or the full path for the root
+	Name string
+	// Totals sums the statements of the node's package and all descendants
+	Totals Totals
+	// Threshold is the aggregate threshold of the subtree, 0 when unset
+	Threshold float64
+	// Coverage is set when the node is itself a tested package
+	Coverage *Coverage
+	Children []*TreeNode
+}
+
+// Passed reports whether the aggregate coverage of the subtree meets its threshold
+func (n *TreeNode) Passed() bool {
+	return n.Threshold == 0 || n.Totals.Percentage() >= n.Threshold
+}
+
+// Walk calls fn for the node and its descendants in depth-first order
+func (n *TreeNode) Walk(fn func(node *TreeNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *TreeNode) walk(fn func(node *TreeNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Tree groups the coverages by import path prefix and rolls up their
+// statement counts at every directory. The root is the longest common
+// prefix of all packages, usually the module path. Subtree thresholds are
+// attached to the nodes they name.
+func (cr *CoverageReporter) Tree(coverages []Coverage) *TreeNode {
+	root, _ := cr.tree(coverages)
+	return root
+}
+
+// tree builds the coverage tree and returns the subtree threshold patterns
+// that name no node
+func (cr *CoverageReporter) tree(coverages []Coverage) (*TreeNode, []string) {
+	root := &TreeNode{}
+	for i := range coverages {
+		cov := &coverages[i]
+		node := root
+		for _, elem := range strings.Split(cov.PackageName, "/") {
+			node = node.child(elem)
+		}
+		node.Coverage = cov
+	}
+
+	// Collapse the common prefix into the root
+	for len(root.Children) == 1 && root.Coverage == nil {
+		root = root.Children[0]
+	}
+	root.Name = root.Path
+	if root.Name == "" {
+		root.Name = "."
+	}
+
+	root.rollup()
+	return root, cr.applySubtreeThresholds(root)
+}
+
+// FailedSubtrees returns the nodes whose aggregate coverage is below their subtree threshold
+func (cr *CoverageReporter) FailedSubtrees(coverages []Coverage) []*TreeNode {
+	root, unmatched := cr.tree(coverages)
+	for _, pattern := range unmatched {
+		log.Warnf("Subtree threshold %s matches no tested packages", pattern)
+	}
+
+	var failed []*TreeNode
+	root.Walk(func(node *TreeNode, _ int) {
+		if !node.Passed() {
+			failed = append(failed, node)
+		}
+	})
+	return failed
+}
+
+// child returns the child named elem, creating it if needed
+func (n *TreeNode) child(elem string) *TreeNode {
+	for _, child := range n.Children {
+		if child.Name == elem {
+			return child
+		}
+	}
+	path := elem
+	if n.Path != "" {
+		path = n.Path + "/" + elem
+	}
+	child := &TreeNode{Path: path, Name: elem}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// rollup sorts the children by name and sums the statements of the subtree
+func (n *TreeNode) rollup() Totals {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+
+	n.Totals = Totals{}
+	if n.Coverage != nil {
+		n.Totals.Statements = n.Coverage.Statements
+		n.Totals.Covered = n.Coverage.CoveredStatements
+	}
+	for _, child := range n.Children {
+		totals := child.rollup()
+		n.Totals.Statements += totals.Statements
+		n.Totals.Covered += totals.Covered
+	}
+	return n.Totals
+}
+
+// applySubtreeThresholds attaches each subtree threshold to the node it names.
+// Patterns are import paths, absolute or relative to the module, optionally
+// ending in "/..."; "..." alone names the root. The patterns that name no
+// node are returned sorted.
+func (cr *CoverageReporter) applySubtreeThresholds(root *TreeNode) []string {
+	nodes := make(map[string]*TreeNode)
+	root.Walk(func(node *TreeNode, _ int) {
+		nodes[node.Path] = node
+	})
+
+	// The root is the longest common prefix of the packages, which may lie
+	// below the module when only part of it is tested
+	modulePath := root.Path
+	if cr.Paths != nil && cr.Paths.ModulePath != "" {
+		modulePath = cr.Paths.ModulePath
+	}
+
+	var unmatched []string
+	for pattern, threshold := range cr.SubtreeThresholds {
+		path := strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "...")
+		path = strings.TrimSuffix(path, "/")
+
+		node, ok := nodes[path]
+		if !ok && (path == "" || path == ".") {
+			node, ok = root, true
+		}
+		if !ok {
+			node, ok = nodes[modulePath+"/"+path]
+		}
+		if !ok {
+			unmatched = append(unmatched, pattern)
+			continue
+		}
+		node.Threshold = threshold
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
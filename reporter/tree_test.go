@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTree(t *testing.T) {
+	cr := &CoverageReporter{SubtreeThresholds: map[string]float64{
+		"internal/...":            75,
+		"example.com/mod/cmd/...": 10,
+		"example.com/mod/missing": 50,
+	}}
+	coverages := []Coverage{
+		{PackageName: "example.com/mod/internal/a", Statements: 10, CoveredStatements: 9},
+		{PackageName: "example.com/mod/internal/b", Statements: 30, CoveredStatements: 15},
+		{PackageName: "example.com/mod/cmd/tool", Statements: 10, CoveredStatements: 5},
+	}
+
+	root, unmatched := cr.tree(coverages)
+	assert.Equal(t, []string{"example.com/mod/missing"}, unmatched)
+	assert.Equal(t, "example.com/mod", root.Name)
+	assert.Equal(t, Totals{Statements: 50, Covered: 29}, root.Totals)
+	require.Len(t, root.Children, 2)
+
+	cmd, internal := root.Children[0], root.Children[1]
+	assert.Equal(t, "example.com/mod/cmd", cmd.Path)
+	assert.Equal(t, 10.0, cmd.Threshold)
+	assert.True(t, cmd.Passed())
+
+	assert.Equal(t, "example.com/mod/internal", internal.Path)
+	assert.Equal(t, Totals{Statements: 40, Covered: 24}, internal.Totals)
+	assert.Equal(t, 60.0, internal.Totals.Percentage())
+	assert.False(t, internal.Passed())
+	require.Len(t, internal.Children, 2)
+	assert.Same(t, &coverages[0], internal.Children[0].Coverage)
+
+	failed := cr.FailedSubtrees(coverages)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "example.com/mod/internal", failed[0].Path)
+}
+
+func TestTreeSubtreeRelativeToModule(t *testing.T) {
+	cr := &CoverageReporter{
+		Paths:             &PathResolver{ModulePath: "example.com/mod"},
+		SubtreeThresholds: map[string]float64{"internal/a/...": 95, "./...": 50},
+	}
+	coverages := []Coverage{
+		{PackageName: "example.com/mod/internal/a", Statements: 10, CoveredStatements: 9},
+		{PackageName: "example.com/mod/internal/b", Statements: 30, CoveredStatements: 15},
+	}
+
+	root, unmatched := cr.tree(coverages)
+	assert.Empty(t, unmatched)
+	assert.Equal(t, "example.com/mod/internal", root.Path)
+	assert.Equal(t, 50.0, root.Threshold)
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, 95.0, root.Children[0].Threshold)
+	assert.False(t, root.Children[0].Passed())
+}
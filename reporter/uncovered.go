@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"sort"
+)
+
+// UncoveredRange is a range of lines in a repository-relative file whose
+// statements were not executed by any test
+type UncoveredRange struct {
+	FileName  string
+	StartLine int
+	EndLine   int
+}
+
+// UncoveredRanges returns the uncovered lines of all packages, grouped into
+// ranges of consecutive lines and sorted by file and line. In diff mode only
+// changed lines are reported.
+func (cr *CoverageReporter) UncoveredRanges(coverages []Coverage) ([]UncoveredRange, error) {
+	merged, err := MergedProfiles(coverages)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []UncoveredRange
+	for _, profile := range merged {
+		fileName := cr.Paths.RelPath(profile.FileName)
+		changedInFile := cr.changedLines[fileName]
+		if cr.Base != "" && len(changedInFile) == 0 {
+			continue
+		}
+
+		var numbers []int
+		for number, count := range LineCounts(profile) {
+			if count == 0 && (cr.Base == "" || changedInFile[number]) {
+				numbers = append(numbers, number)
+			}
+		}
+		sort.Ints(numbers)
+
+		for i, number := range numbers {
+			if i > 0 && number == numbers[i-1]+1 {
+				ranges[len(ranges)-1].EndLine = number
+				continue
+			}
+			ranges = append(ranges, UncoveredRange{FileName: fileName, StartLine: number, EndLine: number})
+		}
+	}
+	return ranges, nil
+}
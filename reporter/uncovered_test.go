@@ -0,0 +1,37 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUncoveredRanges(t *testing.T) {
+	profileFile := filepath.Join(t.TempDir(), "coverage.out")
+	require.NoError(t, os.WriteFile(profileFile, []byte(`mode: set
+example.com/mod/pkg/a.go:3.10,4.2 1 1
+example.com/mod/pkg/a.go:4.2,6.3 2 0
+example.com/mod/pkg/a.go:9.10,10.2 1 0
+`), 0644))
+	coverages := []Coverage{{PackageName: "example.com/mod/pkg", ProfileFile: profileFile}}
+	cr := &CoverageReporter{Paths: &PathResolver{ModulePath: "example.com/mod", ModuleDir: "/src/mod", RootDir: "/src/mod"}}
+
+	ranges, err := cr.UncoveredRanges(coverages)
+	require.NoError(t, err)
+	assert.Equal(t, []UncoveredRange{
+		{FileName: "pkg/a.go", StartLine: 5, EndLine: 6},
+		{FileName: "pkg/a.go", StartLine: 9, EndLine: 10},
+	}, ranges)
+
+	cr.Base = "origin/main"
+	cr.changedLines = ChangedLines{"pkg/a.go": {6: true, 10: true}}
+	ranges, err = cr.UncoveredRanges(coverages)
+	require.NoError(t, err)
+	assert.Equal(t, []UncoveredRange{
+		{FileName: "pkg/a.go", StartLine: 6, EndLine: 6},
+		{FileName: "pkg/a.go", StartLine: 10, EndLine: 10},
+	}, ranges)
+}
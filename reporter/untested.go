@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/mkabdelrahman/coverco/finder"
+)
+
+// Policies for packages without test files
+const (
+	// UntestedFail counts untested packages as failures
+	UntestedFail = "fail"
+	// UntestedIgnore never fails untested packages
+	UntestedIgnore = "ignore"
+	// UntestedThreshold checks untested packages against a separate threshold
+	UntestedThreshold = "threshold"
+)
+
+var ErrUnsupportedUntestedPolicy = fmt.Errorf("unsupported untested packages policy")
+
+// validateUntestedPolicy ensures the policy is one of the supported policies
+func validateUntestedPolicy(policy string) error {
+	switch policy {
+	case UntestedFail, UntestedIgnore, UntestedThreshold:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedUntestedPolicy, policy)
+}
+
+// Untested reports whether the package has no test files
+func (c Coverage) Untested() bool {
+	return c.Status == StatusNoTestFiles
+}
+
+// isUntestedPackage reports whether go list found Go files but no test files in the package
+func isUntestedPackage(pkg finder.Package) bool {
+	return pkg.HasGoFiles() && !pkg.HasTests()
+}
+
+// untestedPassed applies the untested packages policy to a package without test files
+func (cr *CoverageReporter) untestedPassed(cov Coverage) bool {
+	switch cr.UntestedPolicy {
+	case UntestedIgnore:
+		return true
+	case UntestedThreshold:
+		return cov.Percentage >= cr.PackageThreshold(cov.PackageName)
+	}
+	return false
+}
+
+// SplitUntested separates the coverages of untested packages from the others
+func (cr *CoverageReporter) SplitUntested(coverages []Coverage) (tested, untested []Coverage) {
+	for _, cov := range coverages {
+		if cov.Untested() {
+			untested = append(untested, cov)
+		} else {
+			tested = append(tested, cov)
+		}
+	}
+	return tested, untested
+}
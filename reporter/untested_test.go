@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/mkabdelrahman/coverco/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntestedPolicy(t *testing.T) {
+	packages := []finder.Package{
+		{Name: "example.com/mod/untested", GoFiles: []string{"a.go"}, Threshold: 80},
+	}
+	tests := []struct {
+		policy     string
+		percentage float64
+		expected   bool
+	}{
+		{UntestedFail, 100, false},
+		{UntestedIgnore, 0, true},
+		{UntestedThreshold, 9.9, false},
+		{UntestedThreshold, 10, true},
+	}
+
+	for _, tt := range tests {
+		cr := &CoverageReporter{Packages: packages, UntestedPolicy: tt.policy, UntestedThreshold: 10}
+		cov := Coverage{PackageName: "example.com/mod/untested", Status: StatusNoTestFiles, Percentage: tt.percentage}
+		assert.Equal(t, tt.expected, cr.Passed(cov), "%s at %.1f%%", tt.policy, tt.percentage)
+	}
+
+	assert.ErrorIs(t, validateUntestedPolicy("skip"), ErrUnsupportedUntestedPolicy)
+}
+
+func TestPackageThreshold(t *testing.T) {
+	packages := []finder.Package{
+		{Name: "example.com/mod/tested", GoFiles: []string{"a.go"}, TestGoFiles: []string{"a_test.go"}, Threshold: 80},
+		{Name: "example.com/mod/untested", GoFiles: []string{"a.go"}, Threshold: 80},
+	}
+	tests := []struct {
+		policy      string
+		packageName string
+		expected    float64
+	}{
+		{UntestedThreshold, "example.com/mod/untested", 10},
+		{UntestedThreshold, "example.com/mod/tested", 80},
+		{UntestedFail, "example.com/mod/untested", 80},
+		{UntestedIgnore, "example.com/mod/untested", 80},
+		{UntestedThreshold, "example.com/mod/unknown", 70},
+	}
+
+	for _, tt := range tests {
+		cr := &CoverageReporter{Packages: packages, DefaultCoverageThreshold: 70, UntestedPolicy: tt.policy, UntestedThreshold: 10}
+		assert.Equal(t, tt.expected, cr.PackageThreshold(tt.packageName), "%s %s", tt.policy, tt.packageName)
+	}
+}